@@ -0,0 +1,86 @@
+package gobrain
+
+// inputGradient returns the derivatives of an output with respect to the inputs for the last activation of the Network,
+// the derivatives are back propagated through the Layers
+func (nn *Network[T]) inputGradient(output int) []T {
+	_, dhidden := nn.hiddenActivation()
+	_, doutput := nn.outputActivation()
+
+	delta := doutput(nn.OutputActivations[output])
+	gradient := make([]T, nn.NInputs-1)
+	for j := 0; j < nn.NHiddens-1; j++ {
+		axpy(delta*nn.OutputWeights[output][j]*dhidden(nn.HiddenActivations[j]), nn.InputWeights[j][:nn.NInputs-1], gradient)
+	}
+	if len(nn.Layers) == 0 {
+		return gradient
+	}
+
+	// the gradients of the weights of the layers are not needed
+	return append([]T(nil), nn.backward(gradient, nil)...)
+}
+
+/*
+InputGradient returns the derivatives of output 'output' with respect to every input.
+
+The contexts are treated as constant inputs and are left unchanged. The weights and any Gradient
+being accumulated are not touched, so it can be called in the middle of a batch.
+*/
+func (nn *Network[T]) InputGradient(inputs []T, output int) []T {
+	contexts := nn.contexts()
+	defer nn.restoreContexts(contexts)
+	nn.Update(inputs)
+	return nn.inputGradient(output)
+}
+
+// Saliency returns the absolute value of the derivatives of output 'output' with respect to every input
+func (nn *Network[T]) Saliency(inputs []T, output int) []T {
+	saliency := nn.InputGradient(inputs, output)
+	for i, g := range saliency {
+		if g < 0 {
+			saliency[i] = -g
+		}
+	}
+	return saliency
+}
+
+// GradientTimesInput returns the derivatives of output 'output' with respect to every input multiplied by the input
+func (nn *Network[T]) GradientTimesInput(inputs []T, output int) []T {
+	attribution := nn.InputGradient(inputs, output)
+	for i, x := range inputs {
+		attribution[i] *= x
+	}
+	return attribution
+}
+
+/*
+IntegratedGradients returns the attribution of output 'output' to every input, integrating the gradients
+along the straight path from 'baseline', all zeros if nil, to 'inputs' with 'steps' midpoint steps.
+
+The attributions add up to the difference between the output for the inputs and for the baseline,
+up to the error of the integration.
+*/
+func (nn *Network[T]) IntegratedGradients(inputs, baseline []T, output, steps int) []T {
+	if baseline == nil {
+		baseline = make([]T, len(inputs))
+	}
+	if steps < 1 {
+		steps = 1
+	}
+	contexts := nn.contexts()
+	defer nn.restoreContexts(contexts)
+
+	point, sum := make([]T, len(inputs)), make([]T, len(inputs))
+	for k := 0; k < steps; k++ {
+		alpha := (T(k) + .5) / T(steps)
+		for i, x := range inputs {
+			point[i] = baseline[i] + alpha*(x-baseline[i])
+		}
+		nn.restoreContexts(contexts)
+		nn.Update(point)
+		axpy(1, nn.inputGradient(output), sum)
+	}
+	for i, x := range inputs {
+		sum[i] *= (x - baseline[i]) / T(steps)
+	}
+	return sum
+}
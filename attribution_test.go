@@ -0,0 +1,103 @@
+package gobrain
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func testAttribution[T Float](t *testing.T, tolerance float64) {
+	nn := &Network[T]{}
+	nn.SetRand(rand.New(rand.NewSource(1)))
+	nn.Init(3, 5, 2)
+	nn.SetTanhActivation()
+	inputs := []T{0.3, -0.8, 0.5}
+
+	for output := 0; output < 2; output++ {
+		gradient := nn.InputGradient(inputs, output)
+		for i := range inputs {
+			epsilon := 1e-2
+			point := append([]T{}, inputs...)
+			point[i] = inputs[i] + T(epsilon)
+			plus := float64(nn.Update(point)[output])
+			point[i] = inputs[i] - T(epsilon)
+			minus := float64(nn.Update(point)[output])
+			if numerical := (plus - minus) / (2 * epsilon); !closeTo(float64(gradient[i]), numerical, tolerance) {
+				t.Fatalf("output %d input %d: gradient %v, want %v", output, i, gradient[i], numerical)
+			}
+		}
+
+		saliency, product := nn.Saliency(inputs, output), nn.GradientTimesInput(inputs, output)
+		for i, g := range gradient {
+			if saliency[i] != T(math.Abs(float64(g))) || product[i] != g*inputs[i] {
+				t.Fatalf("output %d input %d: saliency %v and gradient times input %v for gradient %v",
+					output, i, saliency[i], product[i], g)
+			}
+		}
+
+		// completeness, the attributions add up to the change of the output from the baseline
+		attribution := nn.IntegratedGradients(inputs, nil, output, 50)
+		var sum float64
+		for _, a := range attribution {
+			sum += float64(a)
+		}
+		difference := float64(nn.Update(inputs)[output])
+		difference -= float64(nn.Update(make([]T, len(inputs)))[output])
+		if !closeTo(sum, difference, tolerance) {
+			t.Fatalf("output %d: attributions add up to %v, want %v", output, sum, difference)
+		}
+	}
+}
+
+func TestAttribution(t *testing.T) {
+	testAttribution[float64](t, 1e-3)
+}
+
+func TestAttribution32(t *testing.T) {
+	testAttribution[float32](t, 1e-2)
+}
+
+func TestAttributionContexts(t *testing.T) {
+	nn, _ := xorNetwork[float64](0, rand.New(rand.NewSource(1)))
+	nn.SetContexts(1, nil)
+	nn.Update([]float64{1, 0})
+	contexts := nn.contexts()
+	nn.IntegratedGradients([]float64{0, 1}, nil, 0, 10)
+	for i, v := range nn.Contexts[0] {
+		if v != contexts[0][i] {
+			t.Fatalf("context %d changed from %v to %v", i, contexts[0][i], v)
+		}
+	}
+}
+
+func TestAttributionBatch(t *testing.T) {
+	data := bumps(3, 16, 4)
+	batch := func(attribute bool) *Gradient[float64] {
+		nn := conv1DNetwork()
+		gradient := nn.NewGradient()
+		for i, sample := range data[:2] {
+			nn.Update(sample.Input)
+			nn.ComputeGradient(sample.Target, gradient)
+			if attribute && i == 0 {
+				nn.InputGradient(data[2].Input, 0)
+				nn.IntegratedGradients(data[2].Input, nil, 0, 5)
+			}
+		}
+		return gradient
+	}
+	want, got := batch(false), batch(true)
+	for i, row := range want.Layers[0] {
+		for j, g := range row {
+			if got.Layers[0][i][j] != g {
+				t.Fatalf("filter %d weight %d: gradient %v with attribution, want %v", i, j, got.Layers[0][i][j], g)
+			}
+		}
+	}
+	for i, row := range want.Input {
+		for j, g := range row {
+			if got.Input[i][j] != g {
+				t.Fatalf("input gradient %d %d is %v with attribution, want %v", i, j, got.Input[i][j], g)
+			}
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package gobrain
+
+import (
+	"math"
+	"math/rand"
+)
+
+/*
+Autoencoder learns to reconstruct its inputs through a smaller hidden layer, the code.
+
+The input to hidden half of the Network is the encoder and the hidden to output half, run with HalfUpdate, is the decoder.
+*/
+type Autoencoder[T Float] struct {
+	Network *Network[T]
+	// Whether the decoder weights are the transpose of the encoder weights, the biases are not tied
+	Tied bool
+	// Target mean activation of the code and weight of the KL divergence penalty from it,
+	// no penalty if the weight is zero. The hidden activations must be in (0, 1) like the ones of sigmoid
+	SparsityTarget, SparsityWeight T
+	// Fraction of the inputs set to zero when training a denoising autoencoder
+	Corruption T
+
+	// Running mean of the activations of the code
+	activity []T
+}
+
+// SetRand sets the random source used to initialize, corrupt and train, it must be called before Init
+func (a *Autoencoder[T]) SetRand(rnd *rand.Rand) {
+	if a.Network == nil {
+		a.Network = &Network[T]{}
+	}
+	a.Network.SetRand(rnd)
+}
+
+// Init initializes an autoencoder of 'inputs' inputs with a code of size 'code'
+func (a *Autoencoder[T]) Init(inputs, code int) {
+	if a.Network == nil {
+		a.Network = &Network[T]{}
+	}
+	a.Network.Init(inputs, code, inputs)
+	if a.Tied {
+		a.tie()
+	}
+}
+
+// tie sets the decoder weights to the transpose of the encoder weights
+func (a *Autoencoder[T]) tie() {
+	nn := a.Network
+	for j := 0; j < nn.NHiddens-1; j++ {
+		for k := 0; k < nn.NInputs-1; k++ {
+			nn.OutputWeights[k][j] = nn.InputWeights[j][k]
+		}
+	}
+}
+
+/*
+Encode returns the code of the inputs.
+
+Like Update, the returned slice is overwritten by the next activation of the Network.
+*/
+func (a *Autoencoder[T]) Encode(inputs []T) []T {
+	nn := a.Network
+	nn.Update(inputs)
+	return nn.HiddenActivations[:nn.NHiddens-1]
+}
+
+// Decode returns the reconstruction of the inputs from a code
+func (a *Autoencoder[T]) Decode(code []T) []T {
+	return a.Network.HalfUpdate(code)
+}
+
+// Reconstruct encodes and decodes the inputs
+func (a *Autoencoder[T]) Reconstruct(inputs []T) []T {
+	return a.Network.Update(inputs)
+}
+
+// corrupted sets a random fraction of the inputs of a dataset to zero, the targets are left unchanged
+type corrupted[T Float] struct {
+	Data     Dataset[T]
+	Fraction T
+	rnd      *rand.Rand
+	input    []T
+}
+
+func (c *corrupted[T]) Len() int {
+	return c.Data.Len()
+}
+
+func (c *corrupted[T]) Sample(i int) Sample[T] {
+	sample := c.Data.Sample(i)
+	c.input = append(c.input[:0], sample.Input...)
+	for j := range c.input {
+		if random[T](c.rnd, 0, 1) < c.Fraction {
+			c.input[j] = 0
+		}
+	}
+	return Sample[T]{Input: c.input, Target: sample.Target}
+}
+
+// penalize adds the gradient of the sparsity penalty and ties the gradients of the tied weights
+func (a *Autoencoder[T]) penalize(gradient *Gradient[T]) {
+	nn := a.Network
+	if a.SparsityWeight != 0 {
+		_, dhidden := nn.hiddenActivation()
+		if len(a.activity) != nn.NHiddens-1 {
+			a.activity = vector[T](nn.NHiddens-1, a.SparsityTarget)
+		}
+		const epsilon = 1e-6
+		rho := a.SparsityTarget
+		for j, h := range nn.HiddenActivations[:nn.NHiddens-1] {
+			a.activity[j] = .99*a.activity[j] + .01*h
+			mean := T(math.Min(math.Max(float64(a.activity[j]), epsilon), 1-epsilon))
+			delta := a.SparsityWeight * (-rho/mean + (1-rho)/(1-mean)) * dhidden(h)
+			axpy(delta, nn.InputActivations, gradient.Input[j])
+		}
+	}
+	if a.Tied {
+		for j := 0; j < nn.NHiddens-1; j++ {
+			for k := 0; k < nn.NInputs-1; k++ {
+				g := gradient.Input[j][k] + gradient.Output[k][j]
+				gradient.Input[j][k], gradient.Output[k][j] = g, g
+			}
+		}
+	}
+}
+
+/*
+Train trains the autoencoder to reconstruct the rows of data and returns the reconstruction errors.
+
+The inputs are corrupted when Corruption is set while the targets stay clean.
+The sparsity penalty and the tying of the weights are applied to the gradient of every sample.
+*/
+func (a *Autoencoder[T]) Train(data [][]T, config Config[T]) []T {
+	samples := make(Samples[T], len(data))
+	for i, row := range data {
+		samples[i] = Sample[T]{Input: row, Target: row}
+	}
+	var set Dataset[T] = samples
+	if a.Corruption > 0 {
+		set = &corrupted[T]{Data: samples, Fraction: a.Corruption, rnd: a.Network.rnd}
+	}
+	return a.Network.TrainDataset(set, func(context *Context[T]) *Context[T] {
+		if config != nil {
+			context = config(context)
+		}
+		onGradient := context.OnGradient
+		context.OnGradient = func(gradient *Gradient[T]) {
+			if onGradient != nil {
+				onGradient(gradient)
+			}
+			a.penalize(gradient)
+		}
+		return context
+	})
+}
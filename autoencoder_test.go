@@ -0,0 +1,72 @@
+package gobrain
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// identity returns the one-hot rows of the identity matrix
+func identity(n int) [][]float64 {
+	rows := make([][]float64, n)
+	for i := range rows {
+		rows[i] = make([]float64, n)
+		rows[i][i] = 1
+	}
+	return rows
+}
+
+func trainAutoencoder(a *Autoencoder[float64], data [][]float64, iterations int) {
+	a.SetRand(rand.New(rand.NewSource(1)))
+	a.Init(len(data[0]), 3)
+	a.Train(data, func(context *Context[float64]) *Context[float64] {
+		context.Iterations = iterations
+		context.Shuffle = true
+		return context
+	})
+}
+
+func TestAutoencoder(t *testing.T) {
+	data := identity(8)
+	for _, a := range []*Autoencoder[float64]{{}, {Tied: true}, {Corruption: .1}} {
+		trainAutoencoder(a, data, 5000)
+		for i, row := range data {
+			code := append([]float64{}, a.Encode(row)...)
+			if len(code) != 3 {
+				t.Fatalf("got a code of size %d, want 3", len(code))
+			}
+			if got, want := class(a.Decode(code)), class(a.Reconstruct(row)); got != want || want != i {
+				t.Errorf("tied %v corruption %v: row %d decoded to %d and reconstructed to %d",
+					a.Tied, a.Corruption, i, got, want)
+			}
+		}
+		if a.Tied {
+			nn := a.Network
+			for j := 0; j < nn.NHiddens-1; j++ {
+				for k := 0; k < nn.NInputs-1; k++ {
+					if nn.OutputWeights[k][j] != nn.InputWeights[j][k] {
+						t.Fatalf("weights %d %d are not tied", j, k)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestSparseAutoencoder(t *testing.T) {
+	activity := func(a *Autoencoder[float64], data [][]float64) float64 {
+		var sum float64
+		for _, row := range data {
+			for _, h := range a.Encode(row) {
+				sum += h
+			}
+		}
+		return sum / float64(len(data)*3)
+	}
+	data := identity(8)
+	dense, sparse := &Autoencoder[float64]{}, &Autoencoder[float64]{SparsityTarget: .05, SparsityWeight: .1}
+	trainAutoencoder(dense, data, 1000)
+	trainAutoencoder(sparse, data, 1000)
+	if d, s := activity(dense, data), activity(sparse, data); s >= d {
+		t.Fatalf("mean activation %v with sparsity and %v without", s, d)
+	}
+}
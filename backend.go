@@ -0,0 +1,105 @@
+package gobrain
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Backend provides the vector kernels used by the networks
+type Backend interface {
+	// Name is used to select the backend with SetBackend
+	Name() string
+	Dot64(X, Y []float64) float64
+	Dot32(X, Y []float32) float32
+	Scal64(alpha float64, X []float64)
+	Scal32(alpha float32, X []float32)
+	Axpy64(alpha float64, X []float64, Y []float64)
+	Axpy32(alpha float32, X []float32, Y []float32)
+}
+
+// BackendEnv is the environment variable used to select the backend at startup
+const BackendEnv = "GOBRAIN_BACKEND"
+
+var (
+	backend  Backend = GoBackend{}
+	backends         = map[string]Backend{}
+)
+
+func init() {
+	RegisterBackend(GoBackend{})
+}
+
+/*
+RegisterBackend makes a backend available to SetBackend.
+
+The backend named by the GOBRAIN_BACKEND environment variable is selected as soon as it is registered.
+*/
+func RegisterBackend(b Backend) {
+	backends[b.Name()] = b
+	if os.Getenv(BackendEnv) == b.Name() {
+		backend = b
+	}
+}
+
+// Backends returns the names of the registered backends
+func Backends() []string {
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetBackend returns the registered backend with the given name
+func GetBackend(name string) (Backend, error) {
+	b, ok := backends[name]
+	if !ok {
+		return nil, fmt.Errorf("gobrain: unknown backend %q", name)
+	}
+	return b, nil
+}
+
+/*
+SetBackend selects the backend used by all networks.
+
+It must not be called while a network is being trained or updated.
+*/
+func SetBackend(name string) error {
+	b, err := GetBackend(name)
+	if err != nil {
+		return err
+	}
+	backend = b
+	return nil
+}
+
+// CurrentBackend returns the backend in use
+func CurrentBackend() Backend {
+	return backend
+}
+
+func dot64(X, Y []float64) float64 {
+	return backend.Dot64(X, Y)
+}
+
+func dot32(X, Y []float32) float32 {
+	return backend.Dot32(X, Y)
+}
+
+func scal64(alpha float64, X []float64) {
+	backend.Scal64(alpha, X)
+}
+
+func scal32(alpha float32, X []float32) {
+	backend.Scal32(alpha, X)
+}
+
+func axpy64(alpha float64, X []float64, Y []float64) {
+	backend.Axpy64(alpha, X, Y)
+}
+
+func axpy32(alpha float32, X []float32, Y []float32) {
+	backend.Axpy32(alpha, X, Y)
+}
@@ -0,0 +1,122 @@
+package gobrain
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func naiveDot(X, Y []float64) float64 {
+	var sum float64
+	for i := range X {
+		sum += X[i] * Y[i]
+	}
+	return sum
+}
+
+func testVectors(rnd *rand.Rand, n int) ([]float64, []float64, []float32, []float32) {
+	x64, y64 := make([]float64, n), make([]float64, n)
+	x32, y32 := make([]float32, n), make([]float32, n)
+	for i := 0; i < n; i++ {
+		x64[i], y64[i] = rnd.Float64()*2-1, rnd.Float64()*2-1
+		x32[i], y32[i] = float32(x64[i]), float32(y64[i])
+	}
+	return x64, y64, x32, y32
+}
+
+func closeTo(a, b, tolerance float64) bool {
+	return math.Abs(a-b) <= tolerance*math.Max(1, math.Abs(b))
+}
+
+func TestGoDot(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for n := 0; n < 67; n++ {
+		x64, y64, _, _ := testVectors(rnd, n)
+		if d, expected := goDot(x64, y64), naiveDot(x64, y64); d != expected {
+			t.Fatalf("goDot(%d) = %v, want %v", n, d, expected)
+		}
+	}
+}
+
+func TestBackends(t *testing.T) {
+	for _, name := range Backends() {
+		b, err := GetBackend(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rnd := rand.New(rand.NewSource(1))
+		for n := 0; n < 67; n++ {
+			x64, y64, x32, y32 := testVectors(rnd, n)
+			expected := naiveDot(x64, y64)
+
+			if d := b.Dot64(x64, y64); !closeTo(d, expected, 1e-12) {
+				t.Fatalf("%s: Dot64(%d) = %v, want %v", name, n, d, expected)
+			}
+			if d := b.Dot32(x32, y32); !closeTo(float64(d), expected, 1e-5) {
+				t.Fatalf("%s: Dot32(%d) = %v, want %v", name, n, d, expected)
+			}
+
+			alpha := rnd.Float64()
+			a64, a32 := append([]float64{}, y64...), append([]float32{}, y32...)
+			b.Axpy64(alpha, x64, a64)
+			b.Axpy32(float32(alpha), x32, a32)
+			for i := 0; i < n; i++ {
+				expected := alpha*x64[i] + y64[i]
+				if !closeTo(a64[i], expected, 1e-15) || !closeTo(float64(a32[i]), expected, 1e-6) {
+					t.Fatalf("%s: Axpy(%d)[%d] = %v %v, want %v", name, n, i, a64[i], a32[i], expected)
+				}
+			}
+
+			b.Scal64(alpha, a64)
+			b.Scal32(float32(alpha), a32)
+			for i := 0; i < n; i++ {
+				expected := alpha * (alpha*x64[i] + y64[i])
+				if !closeTo(a64[i], expected, 1e-15) || !closeTo(float64(a32[i]), expected, 1e-6) {
+					t.Fatalf("%s: Scal(%d)[%d] = %v %v, want %v", name, n, i, a64[i], a32[i], expected)
+				}
+			}
+		}
+	}
+}
+
+func TestSetBackend(t *testing.T) {
+	current := CurrentBackend()
+	defer SetBackend(current.Name())
+
+	if err := SetBackend("go"); err != nil {
+		t.Fatal(err)
+	}
+	if CurrentBackend().Name() != "go" {
+		t.Fatalf("backend is %s", CurrentBackend().Name())
+	}
+	if err := SetBackend("unknown"); err == nil {
+		t.Fatal("expected an error for an unknown backend")
+	}
+}
+
+func BenchmarkBackends(b *testing.B) {
+	x64, y64, x32, y32 := testVectors(rand.New(rand.NewSource(1)), 256)
+	for _, name := range Backends() {
+		backend, _ := GetBackend(name)
+		b.Run(name+"/Dot64", func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				backend.Dot64(x64, y64)
+			}
+		})
+		b.Run(name+"/Dot32", func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				backend.Dot32(x32, y32)
+			}
+		})
+		b.Run(name+"/Axpy64", func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				backend.Axpy64(1e-9, x64, y64)
+			}
+		})
+		b.Run(name+"/Axpy32", func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				backend.Axpy32(1e-9, x32, y32)
+			}
+		})
+	}
+}
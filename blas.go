@@ -0,0 +1,48 @@
+//go:build blas
+// +build blas
+
+package gobrain
+
+import (
+	"os"
+
+	"github.com/ziutek/blas"
+)
+
+// BLASBackend uses the cgo BLAS binding from github.com/ziutek/blas
+type BLASBackend struct{}
+
+func init() {
+	RegisterBackend(BLASBackend{})
+	if os.Getenv(BackendEnv) == "" {
+		backend = BLASBackend{}
+	}
+}
+
+func (BLASBackend) Name() string {
+	return "blas"
+}
+
+func (BLASBackend) Dot64(X, Y []float64) float64 {
+	return blas.Ddot(len(X), X, 1, Y, 1)
+}
+
+func (BLASBackend) Dot32(X, Y []float32) float32 {
+	return blas.Sdot(len(X), X, 1, Y, 1)
+}
+
+func (BLASBackend) Scal64(alpha float64, X []float64) {
+	blas.Dscal(len(X), alpha, X, 1)
+}
+
+func (BLASBackend) Scal32(alpha float32, X []float32) {
+	blas.Sscal(len(X), alpha, X, 1)
+}
+
+func (BLASBackend) Axpy64(alpha float64, X []float64, Y []float64) {
+	blas.Daxpy(len(X), alpha, X, 1, Y, 1)
+}
+
+func (BLASBackend) Axpy32(alpha float32, X []float32, Y []float32) {
+	blas.Saxpy(len(X), alpha, X, 1, Y, 1)
+}
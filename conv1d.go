@@ -0,0 +1,272 @@
+package gobrain
+
+import (
+	"log"
+	"math"
+	"math/rand"
+)
+
+/*
+Conv1D is a 1-D convolution layer with Filters output channels.
+
+Output t of filter f is Activation(sum over c, k of Weights[f][c*Kernel+k] * x[c][t*Stride + k*Dilation - Padding] + bias),
+the inputs outside of [0, Length) are zero. The weights of a filter are followed by its bias.
+*/
+type Conv1D[T Float] struct {
+	// Number of input channels and their length
+	Channels, Length int
+	// Number of filters, which are the output channels, and their size
+	Filters, Kernel int
+	// Stride and dilation, 1 if not set, and zero padding on both sides
+	Stride, Dilation, Padding int
+	Weights, Changes          [][]T
+	// Activation function, ReLU if nil, see NamedActivation for the ones which can be saved
+	Activation, DActivation func(x T) T
+
+	columns, columnDeltas [][]T
+	outputs, deltas       []T
+}
+
+func (c *Conv1D[T]) stride() int {
+	if c.Stride < 1 {
+		return 1
+	}
+	return c.Stride
+}
+
+func (c *Conv1D[T]) dilation() int {
+	if c.Dilation < 1 {
+		return 1
+	}
+	return c.Dilation
+}
+
+// length is the length of the output channels
+func (c *Conv1D[T]) length() int {
+	return (c.Length+2*c.Padding-c.dilation()*(c.Kernel-1)-1)/c.stride() + 1
+}
+
+// Outputs is the number of outputs, Filters times the output length
+func (c *Conv1D[T]) Outputs() int {
+	return c.Filters * c.length()
+}
+
+// Init initializes the weights with random numbers from rnd, or from the global source if rnd is nil
+func (c *Conv1D[T]) Init(rnd *rand.Rand) {
+	if c.length() < 1 {
+		log.Fatal("Error: the kernel is larger than the padded input")
+	}
+	size := c.Channels*c.Kernel + 1
+	c.Weights, c.Changes = matrix[T](c.Filters, size), matrix[T](c.Filters, size)
+	scale := T(math.Sqrt(float64(size)))
+	for _, row := range c.Weights {
+		for i := range row {
+			row[i] = random[T](rnd, -1, 1) / scale
+		}
+	}
+	c.buffers()
+}
+
+func (c *Conv1D[T]) buffers() {
+	if len(c.outputs) == c.Outputs() && len(c.deltas) == c.Channels*c.Length {
+		return
+	}
+	size := c.Channels*c.Kernel + 1
+	c.columns, c.columnDeltas = matrix[T](c.length(), size), matrix[T](c.length(), size)
+	c.outputs, c.deltas = make([]T, c.Outputs()), make([]T, c.Channels*c.Length)
+}
+
+func (c *Conv1D[T]) activationFuncs() []*func(x T) T {
+	return []*func(x T) T{&c.Activation, &c.DActivation}
+}
+
+func (c *Conv1D[T]) activation() (Activation[T], Activation[T]) {
+	if c.Activation == nil {
+		return builtin[T]("relu"), builtin[T]("drelu")
+	}
+	return c.Activation, c.DActivation
+}
+
+// Forward gathers the receptive field of every output into a column and convolves the columns with the filters
+func (c *Conv1D[T]) Forward(inputs []T) []T {
+	if len(inputs) != c.Channels*c.Length {
+		log.Fatal("Error: wrong number of inputs")
+	}
+	c.buffers()
+	activation, _ := c.activation()
+	stride, dilation, kernel := c.stride(), c.dilation(), c.Kernel
+	for t, column := range c.columns {
+		for ch := 0; ch < c.Channels; ch++ {
+			x := inputs[ch*c.Length : (ch+1)*c.Length]
+			for k := 0; k < kernel; k++ {
+				i := t*stride + k*dilation - c.Padding
+				if i >= 0 && i < c.Length {
+					column[ch*kernel+k] = x[i]
+				} else {
+					column[ch*kernel+k] = 0
+				}
+			}
+		}
+		column[len(column)-1] = 1
+	}
+	n := len(c.columns)
+	for f, weights := range c.Weights {
+		for t, column := range c.columns {
+			c.outputs[f*n+t] = activation(dot(weights, column))
+		}
+	}
+	return c.outputs
+}
+
+func (c *Conv1D[T]) Backward(deltas []T, gradient [][]T) []T {
+	_, dactivation := c.activation()
+	for _, row := range c.columnDeltas {
+		for i := range row {
+			row[i] = 0
+		}
+	}
+	n := len(c.columns)
+	for f, weights := range c.Weights {
+		for t, column := range c.columns {
+			d := deltas[f*n+t] * dactivation(c.outputs[f*n+t])
+			if gradient != nil {
+				axpy(d, column, gradient[f])
+			}
+			axpy(d, weights, c.columnDeltas[t])
+		}
+	}
+
+	for i := range c.deltas {
+		c.deltas[i] = 0
+	}
+	stride, dilation, kernel := c.stride(), c.dilation(), c.Kernel
+	for t, column := range c.columnDeltas {
+		for ch := 0; ch < c.Channels; ch++ {
+			x := c.deltas[ch*c.Length : (ch+1)*c.Length]
+			for k := 0; k < kernel; k++ {
+				if i := t*stride + k*dilation - c.Padding; i >= 0 && i < c.Length {
+					x[i] += column[ch*kernel+k]
+				}
+			}
+		}
+	}
+	return c.deltas
+}
+
+func (c *Conv1D[T]) Apply(gradient [][]T, lRate, mFactor T) {
+	applyGradient(c.Weights, c.Changes, gradient, lRate, mFactor)
+}
+
+func (c *Conv1D[T]) NewGradient() [][]T {
+	return matrix[T](c.Filters, c.Channels*c.Kernel+1)
+}
+
+func (c *Conv1D[T]) Copy() Layer[T] {
+	copied := *c
+	copied.Weights, copied.Changes = clone(c.Weights), clone(c.Changes)
+	copied.columns, copied.columnDeltas, copied.outputs, copied.deltas = nil, nil, nil, nil
+	copied.buffers()
+	return &copied
+}
+
+// Pool1D is a 1-D pooling layer, it pools every channel with windows of Size values
+type Pool1D[T Float] struct {
+	Kind PoolKind
+	// Number of channels and their length
+	Channels, Length int
+	// Size of the windows and stride, Size if not set
+	Size, Stride int
+
+	outputs, deltas []T
+	// Index of the maximum of every window
+	max []int
+}
+
+func (p *Pool1D[T]) stride() int {
+	if p.Stride < 1 {
+		return p.Size
+	}
+	return p.Stride
+}
+
+func (p *Pool1D[T]) length() int {
+	return (p.Length-p.Size)/p.stride() + 1
+}
+
+// Outputs is the number of outputs, Channels times the output length
+func (p *Pool1D[T]) Outputs() int {
+	return p.Channels * p.length()
+}
+
+func (p *Pool1D[T]) buffers() {
+	if len(p.outputs) == p.Outputs() && len(p.deltas) == p.Channels*p.Length {
+		return
+	}
+	p.outputs, p.deltas, p.max = make([]T, p.Outputs()), make([]T, p.Channels*p.Length), make([]int, p.Outputs())
+}
+
+func (p *Pool1D[T]) Forward(inputs []T) []T {
+	if len(inputs) != p.Channels*p.Length {
+		log.Fatal("Error: wrong number of inputs")
+	}
+	p.buffers()
+	n, stride := p.length(), p.stride()
+	for ch := 0; ch < p.Channels; ch++ {
+		for t := 0; t < n; t++ {
+			start := ch*p.Length + t*stride
+			window := inputs[start : start+p.Size]
+			o := ch*n + t
+			if p.Kind == AveragePooling {
+				var sum T
+				for _, x := range window {
+					sum += x
+				}
+				p.outputs[o] = sum / T(p.Size)
+				continue
+			}
+			max := 0
+			for i, x := range window {
+				if x > window[max] {
+					max = i
+				}
+			}
+			p.outputs[o], p.max[o] = window[max], start+max
+		}
+	}
+	return p.outputs
+}
+
+func (p *Pool1D[T]) Backward(deltas []T, gradient [][]T) []T {
+	for i := range p.deltas {
+		p.deltas[i] = 0
+	}
+	n, stride := p.length(), p.stride()
+	for ch := 0; ch < p.Channels; ch++ {
+		for t := 0; t < n; t++ {
+			o := ch*n + t
+			if p.Kind == MaxPooling {
+				p.deltas[p.max[o]] += deltas[o]
+				continue
+			}
+			start := ch*p.Length + t*stride
+			for i := start; i < start+p.Size; i++ {
+				p.deltas[i] += deltas[o] / T(p.Size)
+			}
+		}
+	}
+	return p.deltas
+}
+
+// Apply does nothing, pooling layers have no weights
+func (p *Pool1D[T]) Apply(gradient [][]T, lRate, mFactor T) {}
+
+// NewGradient returns nil, pooling layers have no weights
+func (p *Pool1D[T]) NewGradient() [][]T {
+	return nil
+}
+
+func (p *Pool1D[T]) Copy() Layer[T] {
+	copied := *p
+	copied.outputs, copied.deltas, copied.max = nil, nil, nil
+	return &copied
+}
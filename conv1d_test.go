@@ -0,0 +1,203 @@
+package gobrain
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+/*
+checkLayer compares the derivatives computed by Backward with central finite differences
+of the loss sum(r * outputs) for random r, with respect to the inputs and to the weights if not nil.
+*/
+func checkLayer(t *testing.T, name string, layer Layer[float64], inputs []float64, weights [][]float64) {
+	rnd := rand.New(rand.NewSource(1))
+	r := make([]float64, layer.Outputs())
+	for i := range r {
+		r[i] = rnd.Float64()*2 - 1
+	}
+	loss := func() float64 {
+		return dot(r, layer.Forward(inputs))
+	}
+	numerical := func(x *float64) float64 {
+		const epsilon = 1e-6
+		v := *x
+		*x = v + epsilon
+		plus := loss()
+		*x = v - epsilon
+		minus := loss()
+		*x = v
+		return (plus - minus) / (2 * epsilon)
+	}
+
+	gradient := layer.NewGradient()
+	layer.Forward(inputs)
+	deltas := append([]float64{}, layer.Backward(r, gradient)...)
+	for i := range inputs {
+		if n := numerical(&inputs[i]); !closeTo(deltas[i], n, 1e-5) {
+			t.Fatalf("%s: input %d derivative %v, want %v", name, i, deltas[i], n)
+		}
+	}
+	if weights == nil {
+		return
+	}
+	for i, row := range weights {
+		for j := range row {
+			if n := numerical(&row[j]); !closeTo(gradient[i][j], n, 1e-5) {
+				t.Fatalf("%s: weight %d %d derivative %v, want %v", name, i, j, gradient[i][j], n)
+			}
+		}
+	}
+}
+
+func randomInputs(n int, seed int64) []float64 {
+	rnd := rand.New(rand.NewSource(seed))
+	inputs := make([]float64, n)
+	for i := range inputs {
+		inputs[i] = rnd.Float64()*2 - 1
+	}
+	return inputs
+}
+
+func TestConv1DGradients(t *testing.T) {
+	for _, conv := range []*Conv1D[float64]{
+		{Channels: 1, Length: 10, Filters: 2, Kernel: 3},
+		{Channels: 2, Length: 11, Filters: 3, Kernel: 3, Stride: 2, Padding: 1},
+		{Channels: 2, Length: 12, Filters: 2, Kernel: 2, Dilation: 3, Padding: 2},
+	} {
+		conv.Activation, conv.DActivation = tanh[float64], dtanh[float64]
+		conv.Init(rand.New(rand.NewSource(1)))
+		checkLayer(t, "conv", conv, randomInputs(conv.Channels*conv.Length, 2), conv.Weights)
+	}
+	for _, kind := range []PoolKind{MaxPooling, AveragePooling} {
+		for _, pool := range []*Pool1D[float64]{
+			{Kind: kind, Channels: 2, Length: 10, Size: 2},
+			{Kind: kind, Channels: 1, Length: 11, Size: 3, Stride: 2},
+		} {
+			checkLayer(t, "pool", pool, randomInputs(pool.Channels*pool.Length, 3), nil)
+		}
+	}
+}
+
+func TestConv1DShape(t *testing.T) {
+	conv := &Conv1D[float64]{Channels: 1, Length: 5, Filters: 1, Kernel: 3, Padding: 1}
+	conv.Init(nil)
+	conv.Activation, conv.DActivation = linear[float64], dlinear[float64]
+	copy(conv.Weights[0], []float64{1, 2, 3, 0.5})
+	got := conv.Forward([]float64{1, 2, 3, 4, 5})
+	want := []float64{0.5 + 2 + 6, 0.5 + 1 + 4 + 9, 0.5 + 2 + 6 + 12, 0.5 + 3 + 8 + 15, 0.5 + 4 + 10}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+
+	pool := &Pool1D[float64]{Channels: 1, Length: 5, Size: 2}
+	if got := pool.Forward(want); len(got) != 2 || got[0] != want[1] || got[1] != want[3] {
+		t.Fatalf("max pooling of %v is %v", want, got)
+	}
+}
+
+// bumps returns sequences which contain a bump at a random position or only noise
+func bumps(n, length int, seed int64) Samples[float64] {
+	rnd := rand.New(rand.NewSource(seed))
+	data := make(Samples[float64], n)
+	for i := range data {
+		input := make([]float64, length)
+		for j := range input {
+			input[j] = rnd.Float64() * .3
+		}
+		target := float64(i % 2)
+		if target == 1 {
+			p := rnd.Intn(length - 3)
+			input[p], input[p+1], input[p+2] = .5, 1, .5
+		}
+		data[i] = Sample[float64]{Input: input, Target: []float64{target}}
+	}
+	return data
+}
+
+func conv1DNetwork() *Network[float64] {
+	conv := &Conv1D[float64]{Channels: 1, Length: 16, Filters: 4, Kernel: 3}
+	conv.Init(rand.New(rand.NewSource(1)))
+	pool := &Pool1D[float64]{Channels: 4, Length: 14, Size: 14}
+	nn := &Network[float64]{Layers: []Layer[float64]{conv, pool}}
+	nn.SetRand(rand.New(rand.NewSource(1)))
+	nn.Init(pool.Outputs(), 4, 1)
+	return nn
+}
+
+func TestConv1DNetwork(t *testing.T) {
+	nn := conv1DNetwork()
+	nn.TrainDataset(bumps(200, 16, 1), func(context *Context[float64]) *Context[float64] {
+		context.Iterations = 50
+		context.LRate, context.MFactor = .1, .5
+		context.Shuffle = true
+		return context
+	})
+	test := bumps(200, 16, 2)
+	if accuracy := nn.Evaluate(test).Accuracy; accuracy < .9 {
+		t.Fatalf("accuracy %v", accuracy)
+	}
+
+	buffer := &bytes.Buffer{}
+	model := &Model[float64]{Network: nn}
+	if err := model.Save(buffer); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadModel[float64](buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	copied := nn.Copy()
+	for _, sample := range test[:10] {
+		want := nn.Update(sample.Input)[0]
+		if got := loaded.Update(sample.Input)[0]; got != want {
+			t.Fatalf("loaded network outputs %v, want %v", got, want)
+		}
+		if got := copied.Update(sample.Input)[0]; got != want {
+			t.Fatalf("copied network outputs %v, want %v", got, want)
+		}
+	}
+	if gradient := nn.InputGradient(test[1].Input, 0); len(gradient) != 16 {
+		t.Fatalf("got %d input derivatives, want 16", len(gradient))
+	}
+}
+
+func TestConv1DComputeGradient(t *testing.T) {
+	nn := conv1DNetwork()
+	conv := nn.Layers[0].(*Conv1D[float64])
+	sample := bumps(1, 16, 3)[0]
+	loss := func() float64 {
+		d := nn.Update(sample.Input)[0] - sample.Target[0]
+		return d * d / 2
+	}
+
+	nn.Update(sample.Input)
+	gradient := nn.NewGradient()
+	nn.ComputeGradient(sample.Target, gradient)
+	if gradient.Layers[1] != nil {
+		t.Fatal("the pooling layer has a gradient")
+	}
+	if dot(gradient.Layers[0][0], gradient.Layers[0][0]) == 0 {
+		t.Fatal("the gradient of the first filter is zero")
+	}
+	again := nn.NewGradient()
+	nn.ComputeGradient(sample.Target, again)
+	for i, row := range conv.Weights {
+		for j, w := range row {
+			if again.Layers[0][i][j] != gradient.Layers[0][i][j] {
+				t.Fatalf("weight %d %d: ComputeGradient changed from %v to %v", i, j, gradient.Layers[0][i][j], again.Layers[0][i][j])
+			}
+			const epsilon = 1e-6
+			row[j] = w + epsilon
+			plus := loss()
+			row[j] = w - epsilon
+			minus := loss()
+			row[j] = w
+			if n := (plus - minus) / (2 * epsilon); !closeTo(gradient.Layers[0][i][j], n, 1e-6) {
+				t.Fatalf("weight %d %d derivative %v, want %v", i, j, gradient.Layers[0][i][j], n)
+			}
+		}
+	}
+}
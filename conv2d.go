@@ -0,0 +1,303 @@
+package gobrain
+
+import (
+	"log"
+	"math"
+	"math/rand"
+)
+
+/*
+Conv2D is a 2-D convolution layer with Filters output channels and square kernels.
+
+The images are stored channel major and row major within a channel. The receptive fields of the outputs
+are gathered into the rows of a matrix (im2col), so the convolution is a dot product of every row with every filter.
+The weights of a filter are ordered by channel, kernel row and kernel column and are followed by its bias.
+*/
+type Conv2D[T Float] struct {
+	// Number of input channels and their size
+	Channels, Height, Width int
+	// Number of filters, which are the output channels, and the size of their square kernels
+	Filters, Kernel int
+	// Stride, 1 if not set, and zero padding on every side
+	Stride, Padding  int
+	Weights, Changes [][]T
+	// Activation function, ReLU if nil, see NamedActivation for the ones which can be saved
+	Activation, DActivation func(x T) T
+
+	columns, columnDeltas [][]T
+	outputs, deltas       []T
+}
+
+func (c *Conv2D[T]) stride() int {
+	if c.Stride < 1 {
+		return 1
+	}
+	return c.Stride
+}
+
+// size returns the height and width of the output channels
+func (c *Conv2D[T]) size() (int, int) {
+	stride := c.stride()
+	return (c.Height+2*c.Padding-c.Kernel)/stride + 1, (c.Width+2*c.Padding-c.Kernel)/stride + 1
+}
+
+// Outputs is the number of outputs, Filters times the output height and width
+func (c *Conv2D[T]) Outputs() int {
+	height, width := c.size()
+	return c.Filters * height * width
+}
+
+// Init initializes the weights with random numbers from rnd, or from the global source if rnd is nil
+func (c *Conv2D[T]) Init(rnd *rand.Rand) {
+	if height, width := c.size(); height < 1 || width < 1 {
+		log.Fatal("Error: the kernel is larger than the padded input")
+	}
+	size := c.Channels*c.Kernel*c.Kernel + 1
+	c.Weights, c.Changes = matrix[T](c.Filters, size), matrix[T](c.Filters, size)
+	scale := T(math.Sqrt(float64(size)))
+	for _, row := range c.Weights {
+		for i := range row {
+			row[i] = random[T](rnd, -1, 1) / scale
+		}
+	}
+	c.buffers()
+}
+
+func (c *Conv2D[T]) buffers() {
+	if len(c.outputs) == c.Outputs() && len(c.deltas) == c.Channels*c.Height*c.Width {
+		return
+	}
+	height, width := c.size()
+	size := c.Channels*c.Kernel*c.Kernel + 1
+	c.columns, c.columnDeltas = matrix[T](height*width, size), matrix[T](height*width, size)
+	c.outputs, c.deltas = make([]T, c.Outputs()), make([]T, c.Channels*c.Height*c.Width)
+}
+
+func (c *Conv2D[T]) activationFuncs() []*func(x T) T {
+	return []*func(x T) T{&c.Activation, &c.DActivation}
+}
+
+func (c *Conv2D[T]) activation() (Activation[T], Activation[T]) {
+	if c.Activation == nil {
+		return builtin[T]("relu"), builtin[T]("drelu")
+	}
+	return c.Activation, c.DActivation
+}
+
+// im2col calls f with the index of every value of the receptive field of output (y, x) in its column
+// and the index of the input it comes from, or -1 for the padding
+func (c *Conv2D[T]) im2col(y, x int, f func(k, i int)) {
+	stride, kernel := c.stride(), c.Kernel
+	k := 0
+	for ch := 0; ch < c.Channels; ch++ {
+		for ky := 0; ky < kernel; ky++ {
+			row := y*stride + ky - c.Padding
+			for kx := 0; kx < kernel; kx++ {
+				column := x*stride + kx - c.Padding
+				if row >= 0 && row < c.Height && column >= 0 && column < c.Width {
+					f(k, (ch*c.Height+row)*c.Width+column)
+				} else {
+					f(k, -1)
+				}
+				k++
+			}
+		}
+	}
+}
+
+func (c *Conv2D[T]) Forward(inputs []T) []T {
+	if len(inputs) != c.Channels*c.Height*c.Width {
+		log.Fatal("Error: wrong number of inputs")
+	}
+	c.buffers()
+	activation, _ := c.activation()
+	height, width := c.size()
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			column := c.columns[y*width+x]
+			c.im2col(y, x, func(k, i int) {
+				if i < 0 {
+					column[k] = 0
+				} else {
+					column[k] = inputs[i]
+				}
+			})
+			column[len(column)-1] = 1
+		}
+	}
+	n := len(c.columns)
+	for f, weights := range c.Weights {
+		for p, column := range c.columns {
+			c.outputs[f*n+p] = activation(dot(weights, column))
+		}
+	}
+	return c.outputs
+}
+
+func (c *Conv2D[T]) Backward(deltas []T, gradient [][]T) []T {
+	_, dactivation := c.activation()
+	for _, row := range c.columnDeltas {
+		for i := range row {
+			row[i] = 0
+		}
+	}
+	n := len(c.columns)
+	for f, weights := range c.Weights {
+		for p, column := range c.columns {
+			d := deltas[f*n+p] * dactivation(c.outputs[f*n+p])
+			if gradient != nil {
+				axpy(d, column, gradient[f])
+			}
+			axpy(d, weights, c.columnDeltas[p])
+		}
+	}
+
+	// col2im
+	for i := range c.deltas {
+		c.deltas[i] = 0
+	}
+	height, width := c.size()
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			column := c.columnDeltas[y*width+x]
+			c.im2col(y, x, func(k, i int) {
+				if i >= 0 {
+					c.deltas[i] += column[k]
+				}
+			})
+		}
+	}
+	return c.deltas
+}
+
+func (c *Conv2D[T]) Apply(gradient [][]T, lRate, mFactor T) {
+	applyGradient(c.Weights, c.Changes, gradient, lRate, mFactor)
+}
+
+func (c *Conv2D[T]) NewGradient() [][]T {
+	return matrix[T](c.Filters, c.Channels*c.Kernel*c.Kernel+1)
+}
+
+func (c *Conv2D[T]) Copy() Layer[T] {
+	copied := *c
+	copied.Weights, copied.Changes = clone(c.Weights), clone(c.Changes)
+	copied.columns, copied.columnDeltas, copied.outputs, copied.deltas = nil, nil, nil, nil
+	copied.buffers()
+	return &copied
+}
+
+// Pool2D is a 2-D pooling layer, it pools every channel with square windows of Size by Size values
+type Pool2D[T Float] struct {
+	Kind PoolKind
+	// Number of channels and their size
+	Channels, Height, Width int
+	// Size of the windows and stride, Size if not set
+	Size, Stride int
+
+	outputs, deltas []T
+	// Index of the maximum of every window
+	max []int
+}
+
+func (p *Pool2D[T]) stride() int {
+	if p.Stride < 1 {
+		return p.Size
+	}
+	return p.Stride
+}
+
+// size returns the height and width of the output channels
+func (p *Pool2D[T]) size() (int, int) {
+	stride := p.stride()
+	return (p.Height-p.Size)/stride + 1, (p.Width-p.Size)/stride + 1
+}
+
+// Outputs is the number of outputs, Channels times the output height and width
+func (p *Pool2D[T]) Outputs() int {
+	height, width := p.size()
+	return p.Channels * height * width
+}
+
+func (p *Pool2D[T]) buffers() {
+	if len(p.outputs) == p.Outputs() && len(p.deltas) == p.Channels*p.Height*p.Width {
+		return
+	}
+	p.outputs, p.deltas, p.max = make([]T, p.Outputs()), make([]T, p.Channels*p.Height*p.Width), make([]int, p.Outputs())
+}
+
+func (p *Pool2D[T]) Forward(inputs []T) []T {
+	if len(inputs) != p.Channels*p.Height*p.Width {
+		log.Fatal("Error: wrong number of inputs")
+	}
+	p.buffers()
+	height, width := p.size()
+	stride := p.stride()
+	o := 0
+	for ch := 0; ch < p.Channels; ch++ {
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				var sum T
+				max := -1
+				for wy := 0; wy < p.Size; wy++ {
+					start := (ch*p.Height+y*stride+wy)*p.Width + x*stride
+					for i := start; i < start+p.Size; i++ {
+						sum += inputs[i]
+						if max == -1 || inputs[i] > inputs[max] {
+							max = i
+						}
+					}
+				}
+				if p.Kind == AveragePooling {
+					p.outputs[o] = sum / T(p.Size*p.Size)
+				} else {
+					p.outputs[o], p.max[o] = inputs[max], max
+				}
+				o++
+			}
+		}
+	}
+	return p.outputs
+}
+
+func (p *Pool2D[T]) Backward(deltas []T, gradient [][]T) []T {
+	for i := range p.deltas {
+		p.deltas[i] = 0
+	}
+	height, width := p.size()
+	stride := p.stride()
+	o := 0
+	for ch := 0; ch < p.Channels; ch++ {
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				if p.Kind == MaxPooling {
+					p.deltas[p.max[o]] += deltas[o]
+					o++
+					continue
+				}
+				d := deltas[o] / T(p.Size*p.Size)
+				for wy := 0; wy < p.Size; wy++ {
+					start := (ch*p.Height+y*stride+wy)*p.Width + x*stride
+					for i := start; i < start+p.Size; i++ {
+						p.deltas[i] += d
+					}
+				}
+				o++
+			}
+		}
+	}
+	return p.deltas
+}
+
+// Apply does nothing, pooling layers have no weights
+func (p *Pool2D[T]) Apply(gradient [][]T, lRate, mFactor T) {}
+
+// NewGradient returns nil, pooling layers have no weights
+func (p *Pool2D[T]) NewGradient() [][]T {
+	return nil
+}
+
+func (p *Pool2D[T]) Copy() Layer[T] {
+	copied := *p
+	copied.outputs, copied.deltas, copied.max = nil, nil, nil
+	return &copied
+}
@@ -0,0 +1,94 @@
+package gobrain
+
+import (
+	"math/rand"
+	"os"
+	"testing"
+)
+
+func TestConv2DGradients(t *testing.T) {
+	for _, conv := range []*Conv2D[float64]{
+		{Channels: 1, Height: 5, Width: 6, Filters: 2, Kernel: 3},
+		{Channels: 2, Height: 7, Width: 6, Filters: 3, Kernel: 3, Stride: 2, Padding: 1},
+	} {
+		conv.Activation, conv.DActivation = tanh[float64], dtanh[float64]
+		conv.Init(rand.New(rand.NewSource(1)))
+		checkLayer(t, "conv", conv, randomInputs(conv.Channels*conv.Height*conv.Width, 2), conv.Weights)
+	}
+	for _, kind := range []PoolKind{MaxPooling, AveragePooling} {
+		for _, pool := range []*Pool2D[float64]{
+			{Kind: kind, Channels: 2, Height: 6, Width: 4, Size: 2},
+			{Kind: kind, Channels: 1, Height: 7, Width: 7, Size: 3, Stride: 2},
+		} {
+			checkLayer(t, "pool", pool, randomInputs(pool.Channels*pool.Height*pool.Width, 3), nil)
+		}
+	}
+}
+
+func TestConv2DShape(t *testing.T) {
+	conv := &Conv2D[float64]{Channels: 1, Height: 3, Width: 3, Filters: 1, Kernel: 2}
+	conv.Init(nil)
+	conv.Activation, conv.DActivation = linear[float64], dlinear[float64]
+	copy(conv.Weights[0], []float64{1, 0, 0, 1, 0})
+	// the sums of the diagonals of the 2x2 windows
+	got := conv.Forward([]float64{1, 2, 3, 4, 5, 6, 7, 8, 9})
+	want := []float64{6, 8, 12, 14}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+
+	pool := &Pool2D[float64]{Channels: 1, Height: 2, Width: 2, Size: 2, Kind: AveragePooling}
+	if got := pool.Forward(want); len(got) != 1 || got[0] != 10 {
+		t.Fatalf("average pooling of %v is %v", want, got)
+	}
+}
+
+func conv2DNetwork[T Float](rnd *rand.Rand) *Network[T] {
+	conv := &Conv2D[T]{Channels: 1, Height: 8, Width: 8, Filters: 6, Kernel: 3, Padding: 1}
+	conv.Init(rnd)
+	pool := &Pool2D[T]{Channels: 6, Height: 8, Width: 8, Size: 2}
+	nn := &Network[T]{Layers: []Layer[T]{conv, pool}}
+	nn.SetRand(rnd)
+	nn.Init(pool.Outputs(), 16, 4)
+	return nn
+}
+
+func TestConv2DShapes(t *testing.T) {
+	file, err := os.Open("testdata/shapes.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	data, err := ReadCSV[float64](file, CSVConfig{
+		Header:      true,
+		Targets:     []Column{ColumnName("shape")},
+		Categorical: []Column{ColumnName("shape")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Samples) != 200 || len(data.TargetNames) != 4 {
+		t.Fatalf("read %d images of %d shapes", len(data.Samples), len(data.TargetNames))
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	parts := StratifiedSplit(data.Patterns(), rnd, .7, .3)
+	nn := conv2DNetwork[float64](rnd)
+	nn.Train(parts[0], 30, .05, .5, false)
+	if accuracy := nn.Evaluate(Patterns[float64](parts[1])).Accuracy; accuracy < .9 {
+		t.Fatalf("test accuracy %v", accuracy)
+	}
+}
+
+func TestConv2DAllocations(t *testing.T) {
+	nn := conv2DNetwork[float32](rand.New(rand.NewSource(1)))
+	input, target := make([]float32, 64), []float32{1, 0, 0, 0}
+	if allocs := testing.AllocsPerRun(10, func() {
+		nn.Update(input)
+		nn.BackPropagate(target, .1, .5)
+	}); allocs != 0 {
+		t.Fatalf("Update and BackPropagate allocate %v times", allocs)
+	}
+}
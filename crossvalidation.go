@@ -0,0 +1,198 @@
+package gobrain
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// FoldKind selects how the folds of a cross validation are built
+type FoldKind int
+
+const (
+	// KFold shuffles the samples into folds of the same size
+	KFold FoldKind = iota
+	// StratifiedKFold keeps the proportion of every class the same in all the folds
+	StratifiedKFold
+	// TimeSeriesFold splits time ordered samples into consecutive blocks, every fold is
+	// trained on the blocks before the one it is tested on
+	TimeSeriesFold
+)
+
+// CrossValidation configures CrossValidate
+type CrossValidation[T Float] struct {
+	// Number of folds
+	Folds int
+	Kind  FoldKind
+	// Training context of every fold
+	Config Config[T]
+	// Number of folds trained in parallel, GOMAXPROCS if not set
+	Workers int
+	// Random source used to build the folds and to seed the network of every fold, the global source of math/rand if nil
+	Rand *rand.Rand
+}
+
+// FoldResult holds the metrics of a single fold
+type FoldResult struct {
+	Fold        int
+	Train, Test int
+	Metrics     map[string]float64
+}
+
+// CrossValidationResult holds the metrics of every fold and their mean and standard deviation
+type CrossValidationResult struct {
+	Folds     []FoldResult
+	Mean, Std map[string]float64
+}
+
+// folds returns the indexes of the training and test samples of every fold
+func folds[T Float](data Dataset[T], cv CrossValidation[T]) (train, test [][]int) {
+	n := data.Len()
+	switch cv.Kind {
+	case TimeSeriesFold:
+		ends := make([]int, cv.Folds+1)
+		for i := range ends {
+			ends[i] = (i + 1) * n / (cv.Folds + 1)
+		}
+		for k := 0; k < cv.Folds; k++ {
+			train = append(train, indexes(0, ends[k]))
+			test = append(test, indexes(ends[k], ends[k+1]))
+		}
+		return train, test
+	case StratifiedKFold:
+		var classes [][]int
+		for i := 0; i < n; i++ {
+			c := class(data.Sample(i).Target)
+			for len(classes) <= c {
+				classes = append(classes, nil)
+			}
+			classes[c] = append(classes[c], i)
+		}
+		test = make([][]int, cv.Folds)
+		k := 0
+		for _, members := range classes {
+			shuffle(cv.Rand, len(members), func(a, b int) {
+				members[a], members[b] = members[b], members[a]
+			})
+			for _, i := range members {
+				test[k] = append(test[k], i)
+				k = (k + 1) % cv.Folds
+			}
+		}
+	default:
+		order := indexes(0, n)
+		shuffle(cv.Rand, n, func(a, b int) {
+			order[a], order[b] = order[b], order[a]
+		})
+		test = make([][]int, cv.Folds)
+		for j, i := range order {
+			test[j%cv.Folds] = append(test[j%cv.Folds], i)
+		}
+	}
+
+	for k := range test {
+		sort.Ints(test[k])
+		var rest []int
+		for j := range test {
+			if j != k {
+				rest = append(rest, test[j]...)
+			}
+		}
+		sort.Ints(rest)
+		train = append(train, rest)
+	}
+	return train, test
+}
+
+func indexes(start, end int) []int {
+	order := make([]int, end-start)
+	for i := range order {
+		order[i] = start + i
+	}
+	return order
+}
+
+/*
+CrossValidate trains a network created by 'factory' on every fold of a dataset and
+returns the metrics computed on the samples left out of the fold.
+
+The folds are trained in parallel so 'factory' must return independent networks. It is given
+a random source seeded from cv.Rand to initialize the network with, by calling SetRand before Init,
+the same source is then used to train the network so the results only depend on cv.Rand.
+*/
+func CrossValidate[T Float](factory func(rnd *rand.Rand) *Network[T], data Dataset[T], cv CrossValidation[T]) (*CrossValidationResult, error) {
+	if cv.Folds < 2 {
+		return nil, fmt.Errorf("gobrain: at least 2 folds are needed, got %d", cv.Folds)
+	}
+	if data.Len() < cv.Folds+1 {
+		return nil, fmt.Errorf("gobrain: %d samples are not enough for %d folds", data.Len(), cv.Folds)
+	}
+	config := cv.Config
+	if config == nil {
+		config = func(context *Context[T]) *Context[T] {
+			return context
+		}
+	}
+	workers := cv.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	train, test := folds(data, cv)
+	rnds := make([]*rand.Rand, cv.Folds)
+	for k := range rnds {
+		seed := rand.Int63()
+		if cv.Rand != nil {
+			seed = cv.Rand.Int63()
+		}
+		rnds[k] = rand.New(rand.NewSource(seed))
+	}
+	result := &CrossValidationResult{
+		Folds: make([]FoldResult, cv.Folds),
+		Mean:  make(map[string]float64),
+		Std:   make(map[string]float64),
+	}
+	var wait sync.WaitGroup
+	semaphore := make(chan struct{}, workers)
+	for k := 0; k < cv.Folds; k++ {
+		wait.Add(1)
+		semaphore <- struct{}{}
+		go func(k int) {
+			defer func() {
+				<-semaphore
+				wait.Done()
+			}()
+			nn := factory(rnds[k])
+			nn.SetRand(rnds[k])
+			errors := nn.TrainDataset(Subset[T]{Data: data, Indexes: train[k]}, config)
+			metrics := nn.Evaluate(Subset[T]{Data: data, Indexes: test[k]}).Map()
+			if len(errors) > 0 {
+				metrics["train_mse"] = float64(errors[len(errors)-1])
+			}
+			result.Folds[k] = FoldResult{
+				Fold:    k,
+				Train:   len(train[k]),
+				Test:    len(test[k]),
+				Metrics: metrics,
+			}
+		}(k)
+	}
+	wait.Wait()
+
+	for name := range result.Folds[0].Metrics {
+		var sum, squares float64
+		for _, fold := range result.Folds {
+			sum += fold.Metrics[name]
+		}
+		mean := sum / float64(cv.Folds)
+		for _, fold := range result.Folds {
+			squares += (fold.Metrics[name] - mean) * (fold.Metrics[name] - mean)
+		}
+		result.Mean[name] = mean
+		result.Std[name] = math.Sqrt(squares / float64(cv.Folds-1))
+	}
+	return result, nil
+}
@@ -0,0 +1,72 @@
+package gobrain
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func testClassification(n int, seed int64) Samples[float64] {
+	rnd := rand.New(rand.NewSource(seed))
+	data := make(Samples[float64], n)
+	for i := range data {
+		x, y := rnd.Float64(), rnd.Float64()
+		target := 0.0
+		if x > y {
+			target = 1
+		}
+		data[i] = Sample[float64]{Input: []float64{x, y}, Target: []float64{target}}
+	}
+	return data
+}
+
+func TestCrossValidate(t *testing.T) {
+	data := testClassification(200, 1)
+	factory := func(rnd *rand.Rand) *Network[float64] {
+		nn := &Network[float64]{}
+		nn.SetRand(rnd)
+		nn.Init(2, 4, 1)
+		return nn
+	}
+	crossValidate := func(kind FoldKind) (*CrossValidationResult, error) {
+		return CrossValidate(factory, data, CrossValidation[float64]{
+			Folds: 4,
+			Kind:  kind,
+			Rand:  rand.New(rand.NewSource(1)),
+			Config: func(context *Context[float64]) *Context[float64] {
+				context.Iterations = 300
+				context.Shuffle = true
+				return context
+			},
+		})
+	}
+	for _, kind := range []FoldKind{KFold, StratifiedKFold, TimeSeriesFold} {
+		result, err := crossValidate(kind)
+		if err != nil {
+			t.Fatal(err)
+		}
+		samples := 0
+		for _, fold := range result.Folds {
+			samples += fold.Test
+			if kind != TimeSeriesFold && fold.Train+fold.Test != len(data) {
+				t.Fatalf("fold %d has %d training and %d test samples", fold.Fold, fold.Train, fold.Test)
+			}
+		}
+		if kind != TimeSeriesFold && samples != len(data) {
+			t.Fatalf("%d samples were tested", samples)
+		}
+		if result.Mean["accuracy"] < .9 || result.Std["accuracy"] > .1 {
+			t.Fatalf("kind %d: accuracy %v ± %v", kind, result.Mean["accuracy"], result.Std["accuracy"])
+		}
+		again, err := crossValidate(kind)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if again.Mean["mse"] != result.Mean["mse"] {
+			t.Fatalf("kind %d: mean squared error %v and %v for the same random source", kind, result.Mean["mse"], again.Mean["mse"])
+		}
+	}
+
+	if _, err := CrossValidate(factory, data, CrossValidation[float64]{Folds: 1}); err == nil {
+		t.Fatal("expected an error for a single fold")
+	}
+}
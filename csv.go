@@ -0,0 +1,312 @@
+package gobrain
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Column selects a CSV column by name, which requires a header, or by index
+type Column struct {
+	Name  string
+	Index int
+}
+
+// ColumnName selects a column by its name in the header
+func ColumnName(name string) Column {
+	return Column{Name: name}
+}
+
+// ColumnIndex selects a column by its index, starting from 0
+func ColumnIndex(index int) Column {
+	return Column{Index: index}
+}
+
+// Missing is the policy used for missing values
+type Missing int
+
+const (
+	// MissingDrop drops the rows with a missing value
+	MissingDrop Missing = iota
+	// MissingImpute replaces missing values with the mean of the column, or the most frequent category
+	MissingImpute
+	// MissingZero replaces missing values with zero, missing categories are encoded with all zeros
+	MissingZero
+)
+
+// CSVConfig configures ReadCSV
+type CSVConfig struct {
+	// Field delimiter, ',' if not set, use '\t' for TSV
+	Comma rune
+	// Whether the first row is a header
+	Header bool
+	// Input columns, all the columns which are not targets if not set
+	Inputs []Column
+	// Target columns
+	Targets []Column
+	// Categorical columns are one-hot encoded
+	Categorical []Column
+	// Policy for missing values
+	Missing Missing
+	// Values treated as missing, "", "NA", "NaN" and "?" if not set
+	MissingValues []string
+}
+
+// CSVData is a dataset read from CSV
+type CSVData[T Float] struct {
+	Samples[T]
+	// Names of the inputs and targets, one-hot encoded columns are named 'column=category'
+	InputNames, TargetNames []string
+	// Sorted categories of the categorical columns
+	Categories map[string][]string
+}
+
+// Patterns returns the samples in the [][][]T pattern format accepted by Train
+func (d *CSVData[T]) Patterns() [][][]T {
+	return ToPatterns[T](d)
+}
+
+type csvColumn struct {
+	name        string
+	index       int
+	categorical bool
+	values      []float64
+	missing     []bool
+	categories  []string
+	fill        float64
+}
+
+/*
+ReadCSV reads a dataset from CSV or TSV data.
+
+Numeric columns are parsed as floating point values and categorical columns are one-hot encoded.
+*/
+func ReadCSV[T Float](r io.Reader, config CSVConfig) (*CSVData[T], error) {
+	reader := csv.NewReader(r)
+	if config.Comma != 0 {
+		reader.Comma = config.Comma
+	}
+	reader.TrimLeadingSpace = !unicode.IsSpace(reader.Comma)
+	// the line of every record in the file, for the errors
+	var records [][]string
+	var lines []int
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		records, lines = append(records, record), append(lines, line)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("gobrain: empty csv")
+	}
+
+	var header []string
+	if config.Header {
+		header, records, lines = records[0], records[1:], lines[1:]
+	} else {
+		for i := range records[0] {
+			header = append(header, strconv.Itoa(i))
+		}
+	}
+
+	resolve := func(c Column) (int, error) {
+		if c.Name == "" {
+			if c.Index < 0 || c.Index >= len(header) {
+				return 0, fmt.Errorf("gobrain: column index %d out of range", c.Index)
+			}
+			return c.Index, nil
+		}
+		for i, name := range header {
+			if strings.TrimSpace(name) == c.Name {
+				return i, nil
+			}
+		}
+		return 0, fmt.Errorf("gobrain: unknown column %q", c.Name)
+	}
+	resolveAll := func(columns []Column) ([]int, error) {
+		indexes := make([]int, len(columns))
+		for i, c := range columns {
+			index, err := resolve(c)
+			if err != nil {
+				return nil, err
+			}
+			indexes[i] = index
+		}
+		return indexes, nil
+	}
+
+	targets, err := resolveAll(config.Targets)
+	if err != nil {
+		return nil, err
+	}
+	inputs, err := resolveAll(config.Inputs)
+	if err != nil {
+		return nil, err
+	}
+	if len(config.Inputs) == 0 {
+	next:
+		for i := range header {
+			for _, target := range targets {
+				if i == target {
+					continue next
+				}
+			}
+			inputs = append(inputs, i)
+		}
+	}
+	categorical, err := resolveAll(config.Categorical)
+	if err != nil {
+		return nil, err
+	}
+
+	missingValues := config.MissingValues
+	if missingValues == nil {
+		missingValues = []string{"", "NA", "NaN", "?"}
+	}
+	isMissing := func(value string) bool {
+		for _, missing := range missingValues {
+			if value == missing {
+				return true
+			}
+		}
+		return false
+	}
+
+	columns, selected := make(map[int]*csvColumn), []*csvColumn{}
+	for _, index := range append(append([]int{}, inputs...), targets...) {
+		if columns[index] != nil {
+			continue
+		}
+		column := &csvColumn{
+			name:    strings.TrimSpace(header[index]),
+			index:   index,
+			values:  make([]float64, len(records)),
+			missing: make([]bool, len(records)),
+		}
+		for _, c := range categorical {
+			if c == index {
+				column.categorical = true
+			}
+		}
+		columns[index] = column
+		selected = append(selected, column)
+	}
+
+	drop := make([]bool, len(records))
+	for _, column := range selected {
+		var (
+			sum    float64
+			count  int
+			counts = make(map[string]int)
+		)
+		for i, record := range records {
+			value := strings.TrimSpace(record[column.index])
+			if isMissing(value) {
+				column.missing[i] = true
+				if config.Missing == MissingDrop {
+					drop[i] = true
+				}
+				continue
+			}
+			if column.categorical {
+				counts[value]++
+				continue
+			}
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("gobrain: line %d column %q: %v", lines[i], column.name, err)
+			}
+			column.values[i] = v
+			sum += v
+			count++
+		}
+
+		if column.categorical {
+			for category := range counts {
+				column.categories = append(column.categories, category)
+			}
+			sort.Strings(column.categories)
+			index := make(map[string]int, len(column.categories))
+			mode := -1
+			for i, category := range column.categories {
+				index[category] = i
+				if mode == -1 || counts[category] > counts[column.categories[mode]] {
+					mode = i
+				}
+			}
+			for i, record := range records {
+				if !column.missing[i] {
+					column.values[i] = float64(index[strings.TrimSpace(record[column.index])])
+				}
+			}
+			column.fill = -1
+			if config.Missing == MissingImpute {
+				column.fill = float64(mode)
+			}
+		} else if config.Missing == MissingImpute && count > 0 {
+			column.fill = sum / float64(count)
+		}
+	}
+
+	data := &CSVData[T]{
+		Categories: make(map[string][]string),
+	}
+	names := func(indexes []int) []string {
+		var names []string
+		for _, index := range indexes {
+			column := columns[index]
+			if !column.categorical {
+				names = append(names, column.name)
+				continue
+			}
+			data.Categories[column.name] = column.categories
+			for _, category := range column.categories {
+				names = append(names, column.name+"="+category)
+			}
+		}
+		return names
+	}
+	data.InputNames, data.TargetNames = names(inputs), names(targets)
+
+	encode := func(i int, indexes []int, size int) []T {
+		encoded := make([]T, 0, size)
+		for _, index := range indexes {
+			column := columns[index]
+			value := column.values[i]
+			if column.missing[i] {
+				value = column.fill
+			}
+			if !column.categorical {
+				encoded = append(encoded, T(value))
+				continue
+			}
+			for j := range column.categories {
+				if float64(j) == value {
+					encoded = append(encoded, 1)
+				} else {
+					encoded = append(encoded, 0)
+				}
+			}
+		}
+		return encoded
+	}
+	for i := range records {
+		if drop[i] {
+			continue
+		}
+		data.Samples = append(data.Samples, Sample[T]{
+			Input:  encode(i, inputs, len(data.InputNames)),
+			Target: encode(i, targets, len(data.TargetNames)),
+		})
+	}
+	return data, nil
+}
@@ -0,0 +1,76 @@
+package gobrain
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testCSV = `width,height,color,label
+1,2,red,yes
+3,,blue,no
+5,6,?,yes
+7,8,red,no
+`
+
+func TestReadCSV(t *testing.T) {
+	data, err := ReadCSV[float64](strings.NewReader(testCSV), CSVConfig{
+		Header:      true,
+		Targets:     []Column{ColumnName("label")},
+		Categorical: []Column{ColumnName("color"), ColumnIndex(3)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := []string{"width", "height", "color=blue", "color=red"}; !reflect.DeepEqual(data.InputNames, names) {
+		t.Fatalf("input names are %v", data.InputNames)
+	}
+	if names := []string{"label=no", "label=yes"}; !reflect.DeepEqual(data.TargetNames, names) {
+		t.Fatalf("target names are %v", data.TargetNames)
+	}
+	patterns := [][][]float64{
+		{{1, 2, 0, 1}, {0, 1}},
+		{{7, 8, 0, 1}, {1, 0}},
+	}
+	if !reflect.DeepEqual(data.Patterns(), patterns) {
+		t.Fatalf("patterns are %v", data.Patterns())
+	}
+}
+
+func TestReadCSVImpute(t *testing.T) {
+	data, err := ReadCSV[float32](strings.NewReader(strings.ReplaceAll(testCSV, ",", "\t")), CSVConfig{
+		Comma:       '\t',
+		Header:      true,
+		Inputs:      []Column{ColumnIndex(1), ColumnName("color")},
+		Targets:     []Column{ColumnIndex(0)},
+		Categorical: []Column{ColumnName("color")},
+		Missing:     MissingImpute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	patterns := [][][]float32{
+		{{2, 0, 1}, {1}},
+		{{16. / 3, 1, 0}, {3}},
+		{{6, 0, 1}, {5}},
+		{{8, 0, 1}, {7}},
+	}
+	if !reflect.DeepEqual(data.Patterns(), patterns) {
+		t.Fatalf("patterns are %v", data.Patterns())
+	}
+}
+
+func TestReadCSVErrors(t *testing.T) {
+	if _, err := ReadCSV[float64](strings.NewReader(testCSV), CSVConfig{Header: true, Targets: []Column{ColumnName("size")}}); err == nil {
+		t.Fatal("expected an error for an unknown column")
+	}
+	if _, err := ReadCSV[float64](strings.NewReader(testCSV), CSVConfig{Header: true, Targets: []Column{ColumnName("label")}}); err == nil ||
+		!strings.Contains(err.Error(), "line 2 ") {
+		t.Fatalf("expected an error on line 2 for a non numeric column, got %v", err)
+	}
+	// the empty line is skipped but counted
+	if _, err := ReadCSV[float64](strings.NewReader("a,b\n1,2\n\nx,4\n"), CSVConfig{Header: true, Targets: []Column{ColumnName("b")}}); err == nil ||
+		!strings.Contains(err.Error(), "line 4 ") {
+		t.Fatalf("expected an error on line 4, got %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package gobrain
+
+import "sort"
+
+// Sample is an input and the target output expected for it
+type Sample[T Float] struct {
+	Input, Target []T
+}
+
+// Dataset is an indexed collection of samples used for training and evaluation
+type Dataset[T Float] interface {
+	// Len returns the number of samples
+	Len() int
+	// Sample returns the i-th sample
+	Sample(i int) Sample[T]
+}
+
+/*
+Sequenced is optionally implemented by datasets whose samples are grouped into sequences.
+
+The contexts of a network are reset at the start of every sequence.
+*/
+type Sequenced interface {
+	// SequenceStart reports whether the i-th sample is the first one of a sequence
+	SequenceStart(i int) bool
+}
+
+// Patterns adapts the [][][]T pattern format, where p[0] is the input and p[1] is the target, to a Dataset
+type Patterns[T Float] [][][]T
+
+func (p Patterns[T]) Len() int {
+	return len(p)
+}
+
+func (p Patterns[T]) Sample(i int) Sample[T] {
+	return Sample[T]{Input: p[i][0], Target: p[i][1]}
+}
+
+// Samples is an in memory dataset
+type Samples[T Float] []Sample[T]
+
+func (s Samples[T]) Len() int {
+	return len(s)
+}
+
+func (s Samples[T]) Sample(i int) Sample[T] {
+	return s[i]
+}
+
+// Sequences is an in memory dataset of sample sequences
+type Sequences[T Float] struct {
+	sequences [][]Sample[T]
+	offsets   []int
+}
+
+// NewSequences creates a dataset from sample sequences
+func NewSequences[T Float](sequences [][]Sample[T]) *Sequences[T] {
+	s := &Sequences[T]{
+		sequences: sequences,
+		offsets:   make([]int, len(sequences)+1),
+	}
+	for i, sequence := range sequences {
+		s.offsets[i+1] = s.offsets[i] + len(sequence)
+	}
+	return s
+}
+
+func (s *Sequences[T]) Len() int {
+	return s.offsets[len(s.sequences)]
+}
+
+func (s *Sequences[T]) locate(i int) (int, int) {
+	j := sort.SearchInts(s.offsets, i+1) - 1
+	return j, i - s.offsets[j]
+}
+
+func (s *Sequences[T]) Sample(i int) Sample[T] {
+	j, k := s.locate(i)
+	return s.sequences[j][k]
+}
+
+func (s *Sequences[T]) SequenceStart(i int) bool {
+	_, k := s.locate(i)
+	return k == 0
+}
+
+/*
+Lazy is a random access dataset whose samples are produced on demand instead of being held in memory,
+for example by reading them from disk at an offset or generating them.
+
+N is the number of samples and Load is called every time a sample is needed, with any index
+in any order since training may shuffle and evaluation revisits the samples. It is not a
+forward only stream, such a source has to be buffered or indexed first.
+*/
+type Lazy[T Float] struct {
+	N    int
+	Load func(i int) Sample[T]
+}
+
+func (l Lazy[T]) Len() int {
+	return l.N
+}
+
+func (l Lazy[T]) Sample(i int) Sample[T] {
+	return l.Load(i)
+}
+
+// ToPatterns converts a dataset to the [][][]T pattern format
+func ToPatterns[T Float](data Dataset[T]) [][][]T {
+	patterns := make([][][]T, data.Len())
+	for i := range patterns {
+		sample := data.Sample(i)
+		patterns[i] = [][]T{sample.Input, sample.Target}
+	}
+	return patterns
+}
+
+// Subset is a view of the samples of a dataset selected by their indexes
+type Subset[T Float] struct {
+	Data    Dataset[T]
+	Indexes []int
+}
+
+func (s Subset[T]) Len() int {
+	return len(s.Indexes)
+}
+
+func (s Subset[T]) Sample(i int) Sample[T] {
+	return s.Data.Sample(s.Indexes[i])
+}
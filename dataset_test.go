@@ -0,0 +1,65 @@
+package gobrain
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSequences(t *testing.T) {
+	sample := func(x float64) Sample[float64] {
+		return Sample[float64]{Input: []float64{x}, Target: []float64{x}}
+	}
+	data := NewSequences([][]Sample[float64]{
+		{sample(0), sample(1), sample(2)},
+		{},
+		{sample(3), sample(4)},
+	})
+	if data.Len() != 5 {
+		t.Fatalf("got %d samples, want 5", data.Len())
+	}
+	for i := 0; i < data.Len(); i++ {
+		if x := data.Sample(i).Input[0]; x != float64(i) {
+			t.Fatalf("sample %d is %v", i, x)
+		}
+		if start := data.SequenceStart(i); start != (i == 0 || i == 3) {
+			t.Fatalf("SequenceStart(%d) = %v", i, start)
+		}
+	}
+}
+
+func TestTrainDataset(t *testing.T) {
+	nn, patterns := xorNetwork[float64](0, rand.New(rand.NewSource(1)))
+	loaded := 0
+	data := Lazy[float64]{
+		N: len(patterns),
+		Load: func(i int) Sample[float64] {
+			loaded++
+			return Patterns[float64](patterns).Sample(i)
+		},
+	}
+	errors := nn.TrainDataset(data, func(context *Context[float64]) *Context[float64] {
+		context.Iterations = 2000
+		return context
+	})
+	if loaded != 2000*len(patterns) {
+		t.Fatalf("loaded %d samples", loaded)
+	}
+	if e := errors[len(errors)-1]; e > .01 {
+		t.Fatalf("error after training is %v", e)
+	}
+
+	nn.SetContexts(2, nil)
+	nn.Contexts[0][0] = 1
+	nn.TrainDataset(NewSequences([][]Sample[float64]{Samples[float64]{{Input: []float64{0, 0}, Target: []float64{0}}}}),
+		func(context *Context[float64]) *Context[float64] {
+			// the second epoch resets the contexts after they have been updated
+			context.Iterations = 2
+			return context
+		})
+	if len(nn.Contexts) != 2 || nn.Contexts[1][0] != .5 {
+		t.Fatal("contexts were not reset at the start of the sequence")
+	}
+	if bias := nn.HiddenActivations[nn.NHiddens-1]; bias != 1 {
+		t.Fatalf("the bias of the hidden layer is %v after resetting the contexts", bias)
+	}
+}
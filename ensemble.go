@@ -0,0 +1,308 @@
+package gobrain
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"math"
+	"math/rand"
+	"os"
+	"runtime"
+	"sync"
+)
+
+// Combination selects how the outputs of the members of an ensemble are combined
+type Combination int
+
+const (
+	// Average averages the outputs of the members
+	Average Combination = iota
+	// Vote outputs the fraction of the members predicting every class,
+	// for a single output the fraction predicting class 1
+	Vote
+	// Stacking combines the outputs of the members with a meta network trained on them
+	Stacking
+)
+
+// Ensemble combines independently initialized and trained networks
+type Ensemble[T Float] struct {
+	Members     []*Network[T]
+	Combination Combination
+	// Meta network of Stacking, its inputs are the outputs of all the members, created by TrainDataset if nil
+	Meta *Network[T]
+	// Number of folds of the out of fold predictions the meta network is trained on, 5 if not set
+	Folds int
+	// Whether every member is trained on a bootstrap resample of the training data
+	Bagging bool
+	// Number of members trained in parallel, GOMAXPROCS if not set
+	Workers int
+	// Random source of the members and the resamples, the global source of math/rand if nil
+	Rand *rand.Rand
+
+	outputs, inputs []T
+	output          io.Writer
+	logger          *slog.Logger
+}
+
+// NewEnsemble creates an ensemble of 'members' networks created by 'factory', which should initialize them
+func NewEnsemble[T Float](factory func() *Network[T], members int) *Ensemble[T] {
+	e := &Ensemble[T]{Members: make([]*Network[T], members)}
+	for i := range e.Members {
+		e.Members[i] = factory()
+	}
+	return e
+}
+
+// SetOutput sets the writer Test output is printed to, os.Stdout is used if nil
+func (e *Ensemble[T]) SetOutput(w io.Writer) {
+	e.output = w
+}
+
+// SetLogger sets a structured logger for Test output and for the debug output of the members when training
+func (e *Ensemble[T]) SetLogger(logger *slog.Logger) {
+	e.logger = logger
+	for _, member := range e.Members {
+		member.SetLogger(logger)
+	}
+	if e.Meta != nil {
+		e.Meta.SetLogger(logger)
+	}
+}
+
+// ResetContexts resets the contexts of the members and of the meta network
+func (e *Ensemble[T]) ResetContexts() {
+	for _, member := range e.Members {
+		member.ResetContexts()
+	}
+	if e.Meta != nil {
+		e.Meta.ResetContexts()
+	}
+}
+
+func (e *Ensemble[T]) seed() int64 {
+	if e.Rand != nil {
+		return e.Rand.Int63()
+	}
+	return rand.Int63()
+}
+
+// Train trains the ensemble like Network.Train, see TrainDataset
+func (e *Ensemble[T]) Train(patterns [][][]T, iterations int, lRate, mFactor T, debug bool) [][]T {
+	config := func(context *Context[T]) *Context[T] {
+		context.Iterations = iterations
+		context.LRate = lRate
+		context.MFactor = mFactor
+		context.Debug = debug
+		return context
+	}
+	return e.TrainWithConfig(patterns, config)
+}
+
+// TrainWithConfig trains the ensemble on patterns, see TrainDataset
+func (e *Ensemble[T]) TrainWithConfig(patterns [][][]T, config Config[T]) [][]T {
+	return e.TrainDataset(Patterns[T](patterns), config)
+}
+
+/*
+TrainDataset trains the members in parallel and returns the training errors of every member.
+
+Every member gets its own random source seeded from Rand, so the members see different orders of the samples
+and, with Bagging, different resamples. The config is called by every member concurrently.
+
+The meta network of Stacking is trained on out of fold predictions: the samples are split into Folds folds
+and copies of the untrained members are trained on all the folds but one, the meta network learns from their
+outputs for the samples of that fold, which they haven't been trained on.
+*/
+func (e *Ensemble[T]) TrainDataset(data Dataset[T], config Config[T]) [][]T {
+	var untrained []*Network[T]
+	if e.Combination == Stacking {
+		untrained = make([]*Network[T], len(e.Members))
+		for i, member := range e.Members {
+			untrained[i] = member.Copy()
+		}
+	}
+
+	sets := make([]Dataset[T], len(e.Members))
+	for i := range sets {
+		sets[i] = data
+	}
+	errors := e.train(e.Members, sets, config)
+	if e.Combination == Stacking {
+		e.trainMeta(untrained, data, config)
+	}
+	return errors
+}
+
+// train trains every network on its dataset in parallel, every one with its own random source
+func (e *Ensemble[T]) train(networks []*Network[T], sets []Dataset[T], config Config[T]) [][]T {
+	workers := e.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	rnds := make([]*rand.Rand, len(networks))
+	for i, data := range sets {
+		rnds[i] = rand.New(rand.NewSource(e.seed()))
+		if e.Bagging {
+			indexes := make([]int, data.Len())
+			for j := range indexes {
+				indexes[j] = rnds[i].Intn(data.Len())
+			}
+			sets[i] = Subset[T]{Data: data, Indexes: indexes}
+		}
+	}
+
+	errors := make([][]T, len(networks))
+	var wait sync.WaitGroup
+	semaphore := make(chan struct{}, workers)
+	for i, nn := range networks {
+		wait.Add(1)
+		semaphore <- struct{}{}
+		go func(i int, nn *Network[T]) {
+			defer func() {
+				<-semaphore
+				wait.Done()
+			}()
+			nn.SetRand(rnds[i])
+			errors[i] = nn.TrainDataset(sets[i], config)
+		}(i, nn)
+	}
+	wait.Wait()
+	return errors
+}
+
+// trainMeta trains the meta network on the out of fold predictions of copies of the untrained members
+func (e *Ensemble[T]) trainMeta(untrained []*Network[T], data Dataset[T], config Config[T]) {
+	k := e.Folds
+	if k < 2 {
+		k = 5
+	}
+	if k > data.Len() {
+		k = data.Len()
+	}
+	train, test := folds(data, CrossValidation[T]{Folds: k, Kind: KFold, Rand: rand.New(rand.NewSource(e.seed()))})
+	members := len(untrained)
+	networks, sets := make([]*Network[T], k*members), make([]Dataset[T], k*members)
+	for f := range train {
+		for m, member := range untrained {
+			networks[f*members+m] = member.Copy()
+			networks[f*members+m].SetLogger(e.logger)
+			sets[f*members+m] = Subset[T]{Data: data, Indexes: train[f]}
+		}
+	}
+	e.train(networks, sets, config)
+
+	samples := make(Samples[T], data.Len())
+	for f, indexes := range test {
+		for _, i := range indexes {
+			sample := data.Sample(i)
+			var inputs []T
+			for _, nn := range networks[f*members : (f+1)*members] {
+				inputs = append(inputs, nn.Update(sample.Input)...)
+			}
+			samples[i] = Sample[T]{Input: inputs, Target: sample.Target}
+		}
+	}
+	if e.Meta == nil {
+		first := e.Members[0]
+		e.Meta = &Network[T]{Regression: first.Regression}
+		e.Meta.SetRand(rand.New(rand.NewSource(e.seed())))
+		e.Meta.Init(len(e.Members)*first.NOutputs, len(e.Members), first.NOutputs)
+		e.Meta.SetLogger(e.logger)
+	}
+	e.Meta.TrainDataset(samples, config)
+}
+
+// stack returns the concatenated outputs of the members
+func (e *Ensemble[T]) stack(inputs []T) []T {
+	e.inputs = e.inputs[:0]
+	for _, member := range e.Members {
+		e.inputs = append(e.inputs, member.Update(inputs)...)
+	}
+	return e.inputs
+}
+
+// Update activates the ensemble and combines the outputs of the members
+func (e *Ensemble[T]) Update(inputs []T) []T {
+	if e.Combination == Stacking {
+		if e.Meta == nil {
+			log.Fatal("Error: the meta network of the ensemble is not trained")
+		}
+		return e.Meta.Update(e.stack(inputs))
+	}
+
+	outputs := e.Members[0].NOutputs
+	if len(e.outputs) != outputs {
+		e.outputs = make([]T, outputs)
+	}
+	for i := range e.outputs {
+		e.outputs[i] = 0
+	}
+	weight := 1 / T(len(e.Members))
+	for _, member := range e.Members {
+		y := member.Update(inputs)
+		switch {
+		case e.Combination != Vote:
+			axpy(weight, y, e.outputs)
+		case outputs == 1:
+			e.outputs[0] += T(class(y)) * weight
+		default:
+			e.outputs[class(y)] += weight
+		}
+	}
+	return e.outputs
+}
+
+// Disagreement returns the standard deviation of the outputs of the members for every output
+func (e *Ensemble[T]) Disagreement(inputs []T) []T {
+	outputs := e.Members[0].NOutputs
+	means, squares := make([]float64, outputs), make([]float64, outputs)
+	for k, member := range e.Members {
+		for i, y := range member.Update(inputs) {
+			d := float64(y) - means[i]
+			means[i] += d / float64(k+1)
+			squares[i] += d * (float64(y) - means[i])
+		}
+	}
+	std := make([]T, outputs)
+	if len(e.Members) > 1 {
+		for i := range std {
+			std[i] = T(math.Sqrt(squares[i] / float64(len(e.Members)-1)))
+		}
+	}
+	return std
+}
+
+// Evaluate computes the metrics of the ensemble on a dataset, like Network.Evaluate, resetting the contexts at the start of every sequence
+func (e *Ensemble[T]) Evaluate(data Dataset[T]) Metrics {
+	return evaluate[T](e, data, e.Members[0].Regression)
+}
+
+func (e *Ensemble[T]) Test(patterns [][][]T) {
+	e.TestDataset(Patterns[T](patterns))
+}
+
+/*
+TestDataset prints the output of the ensemble next to the target for every sample of a dataset.
+
+If the dataset is Sequenced the contexts are reset at the start of every sequence.
+*/
+func (e *Ensemble[T]) TestDataset(data Dataset[T]) {
+	w := e.output
+	if w == nil {
+		w = os.Stdout
+	}
+	sequenced, _ := data.(Sequenced)
+	for i := 0; i < data.Len(); i++ {
+		if sequenced != nil && sequenced.SequenceStart(i) {
+			e.ResetContexts()
+		}
+		p := data.Sample(i)
+		outputs := e.Update(p.Input)
+		if e.logger != nil {
+			e.logger.Info("test", "input", p.Input, "output", outputs, "target", p.Target)
+		} else {
+			fmt.Fprintln(w, p.Input, "->", outputs, " : ", p.Target)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package gobrain
+
+import (
+	"bytes"
+	"log/slog"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func testEnsemble(combination Combination, bagging bool, workers int) *Ensemble[float64] {
+	rnd := rand.New(rand.NewSource(1))
+	e := NewEnsemble(func() *Network[float64] {
+		nn := &Network[float64]{}
+		nn.SetRand(rnd)
+		nn.Init(2, 4, 1)
+		return nn
+	}, 5)
+	e.Combination, e.Bagging, e.Workers = combination, bagging, workers
+	e.Rand = rand.New(rand.NewSource(2))
+	e.TrainDataset(testClassification(200, 1), func(context *Context[float64]) *Context[float64] {
+		context.Iterations = 100
+		return context
+	})
+	return e
+}
+
+func TestEnsemble(t *testing.T) {
+	test := testClassification(200, 2)
+	for _, combination := range []Combination{Average, Vote, Stacking} {
+		for _, bagging := range []bool{false, true} {
+			e := testEnsemble(combination, bagging, 4)
+			if accuracy := e.Evaluate(test).Accuracy; accuracy < .9 {
+				t.Errorf("combination %d bagging %v: accuracy %v", combination, bagging, accuracy)
+			}
+		}
+	}
+
+	e := testEnsemble(Vote, true, 4)
+	for _, sample := range test[:20] {
+		y := e.Update(sample.Input)[0]
+		if votes := y * 5; !closeTo(votes, float64(int(votes+.5)), 1e-12) {
+			t.Fatalf("got %v, want a fraction of 5 votes", y)
+		}
+	}
+}
+
+func TestEnsembleDeterminism(t *testing.T) {
+	a, b := testEnsemble(Average, true, 1), testEnsemble(Average, true, 4)
+	input := []float64{.3, .6}
+	if ya, yb := a.Update(input)[0], b.Update(input)[0]; ya != yb {
+		t.Fatalf("got %v with 1 worker and %v with 4", ya, yb)
+	}
+}
+
+func TestEnsembleDisagreement(t *testing.T) {
+	e := testEnsemble(Average, true, 4)
+	// near the decision boundary the members disagree more than far from it
+	boundary, far := e.Disagreement([]float64{.5, .5})[0], e.Disagreement([]float64{.95, .05})[0]
+	if boundary <= 0 || boundary <= far {
+		t.Fatalf("disagreement %v on the boundary and %v far from it", boundary, far)
+	}
+
+	buffer := &bytes.Buffer{}
+	e.SetOutput(buffer)
+	e.Test([][][]float64{{{.9, .1}, {1}}, {{.1, .9}, {0}}})
+	if lines := strings.Count(buffer.String(), "\n"); lines != 2 {
+		t.Fatalf("got %d lines of output, want 2", lines)
+	}
+}
+
+func TestEnsembleTrain(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	e := NewEnsemble(func() *Network[float64] {
+		nn := &Network[float64]{}
+		nn.SetRand(rnd)
+		nn.Init(2, 4, 1)
+		nn.SetContexts(1, nil)
+		return nn
+	}, 3)
+	e.Combination, e.Folds = Stacking, 3
+	e.Rand = rand.New(rand.NewSource(2))
+	buffer := &bytes.Buffer{}
+	e.SetLogger(slog.New(slog.NewTextHandler(buffer, nil)))
+	patterns := ToPatterns[float64](testClassification(60, 1))
+	if errors := e.Train(patterns, 1, .6, .4, true); len(errors) != 3 || len(errors[0]) != 1 {
+		t.Fatalf("got the errors %v", errors)
+	}
+	// the members, the copies trained on the 3 folds and the meta network
+	if lines := strings.Count(buffer.String(), "msg=training iteration=0"); lines != 3+3*3+1 {
+		t.Fatalf("got %d training logs, want 13", lines)
+	}
+
+	samples := make([]Sample[float64], len(patterns))
+	for i := range samples {
+		samples[i] = Patterns[float64](patterns).Sample(i)
+	}
+	once := e.Evaluate(NewSequences([][]Sample[float64]{samples}))
+	twice := e.Evaluate(NewSequences([][]Sample[float64]{samples, samples}))
+	if !closeTo(once.MSE, twice.MSE, 1e-12) {
+		t.Fatalf("mean squared error %v for a sequence and %v for it twice, the contexts were not reset", once.MSE, twice.MSE)
+	}
+}
@@ -3,29 +3,112 @@ package gobrain
 
 import (
 	"fmt"
+	"io"
 	"log"
+	"log/slog"
 	"math"
 	"math/rand"
+	"os"
 )
 
-// FeedForwad struct is used to represent a simple neural network
-type FeedForward struct {
+// Float is the set of floating point types a network can be built with
+type Float interface {
+	float32 | float64
+}
+
+// FeedForward is a neural network using float64 values
+type FeedForward = Network[float64]
+
+// FeedForward32 is a neural network using float32 values
+type FeedForward32 = Network[float32]
+
+// Activation32 is an activation function for FeedForward32
+type Activation32 = Activation[float32]
+
+// Context32 is the training context for FeedForward32
+type Context32 = Context[float32]
+
+// Config32 configures the training context for FeedForward32
+type Config32 = Config[float32]
+
+// Network struct is used to represent a simple neural network
+type Network[T Float] struct {
 	// Number of input, hidden and output nodes
 	NInputs, NHiddens, NOutputs int
 	// Whether it is regression or not
 	Regression bool
 	// Activations for nodes
-	InputActivations, HiddenActivations, OutputActivations []float64
+	InputActivations, HiddenActivations, OutputActivations []T
 	// ElmanRNN contexts
-	Contexts [][]float64
-	// Weights
-	InputWeights, OutputWeights [][]float64
-	// Last change in weights for momentum
-	InputChanges, OutputChanges [][]float64
+	Contexts [][]T
+	// Weights, rows are views of a contiguous row major buffer
+	InputWeights, OutputWeights [][]T
+	// Last change in weights for momentum, in the same orientation as the weights
+	InputChanges, OutputChanges [][]T
+	// Version of the layout of the weights and changes, set by Init. Before version 1 the changes
+	// were transposed, NInputs x NHiddens and NHiddens x NOutputs, a network decoded from data saved
+	// with that layout is migrated by LoadModel or the first time it is trained
+	Version int
 	// Set for dropout
-	Dropout float64
+	Dropout T
+	// Shuffle the samples every epoch when training, it is the default of Context.Shuffle
+	// so that Train, which takes no context, can shuffle with the random source of the Network
+	Shuffle bool
+	// Layers applied in order to the inputs before the dense layers, like convolutions,
+	// the number of inputs of Init is then the number of outputs of the last layer
+	Layers []Layer[T]
+	// Activation function
+	Activation  func(x T) T
+	DActivation func(y T) T
+	// Per layer activation functions, Activation and DActivation are used for the ones not set
+	HiddenActivation, OutputActivation   func(x T) T
+	DHiddenActivation, DOutputActivation func(y T) T
+
+	// Scratch buffers used by BackPropagate
+	outputDeltas, hiddenDeltas, inputDeltas []T
+	gradient                                *Gradient[T]
+	// Scratch buffers of the noise injected when training
+	noise [][]T
+	// Order of the samples when training
+	order []int
+	// Random source, the global one of math/rand if nil
+	rnd *rand.Rand
+	// Destinations of Test and debug output, os.Stdout if both are nil
+	output io.Writer
+	logger *slog.Logger
+}
+
+// Activation is an activation function or its derivative
+type Activation[T Float] func(x T) T
+
+// Context holds the parameters used for training
+type Context[T Float] struct {
+	Iterations     int
+	LRate, MFactor T
+	Debug          bool
+	// Shuffle the samples, or the sequences of a Sequenced dataset, every epoch
+	Shuffle bool
+
+	Activations []Activation[T]
+
+	// Noise injected into the layers every step, no noise if nil
+	Noise *Noise[T]
+
+	// Called before every epoch
+	OnEpochStart func(epoch int)
+	// Called after every epoch with its mean error, training stops if it returns true
+	OnEpochEnd func(epoch int, err T) (stop bool)
+	// Called after the weights are updated with a sample, the weights are updated one sample at a time
+	OnBatchEnd func(epoch, sample int, err T)
+	// Called after an epoch with a lower mean error than all the previous ones
+	OnImprovement func(epoch int, err T)
+	// Called with the gradient of every sample before it is applied, it can modify the gradient
+	OnGradient func(gradient *Gradient[T])
 }
 
+// Config is used to modify the default training context
+type Config[T Float] func(context *Context[T]) *Context[T]
+
 /*
 Initialize the neural network;
 
@@ -33,368 +116,211 @@ the 'inputs' value is the number of inputs the network will have,
 the 'hiddens' value is the number of hidden nodes and
 the 'outputs' value is the number of the outputs of the network.
 */
-func (nn *FeedForward) Init(inputs, hiddens, outputs int) {
+func (nn *Network[T]) Init(inputs, hiddens, outputs int) {
 	nn.NInputs = inputs + 1   // +1 for bias
 	nn.NHiddens = hiddens + 1 // +1 for bias
 	nn.NOutputs = outputs
 
-	nn.InputActivations = vector(nn.NInputs, 1.0)
-	nn.HiddenActivations = vector(nn.NHiddens, 1.0)
-	nn.OutputActivations = vector(nn.NOutputs, 1.0)
+	nn.InputActivations = vector[T](nn.NInputs, 1.0)
+	nn.HiddenActivations = vector[T](nn.NHiddens, 1.0)
+	nn.OutputActivations = vector[T](nn.NOutputs, 1.0)
 
-	nn.InputWeights = matrix(nn.NHiddens, nn.NInputs)
-	nn.OutputWeights = matrix(nn.NOutputs, nn.NHiddens)
+	nn.InputWeights = matrix[T](nn.NHiddens, nn.NInputs)
+	nn.OutputWeights = matrix[T](nn.NOutputs, nn.NHiddens)
 
+	// http://stats.stackexchange.com/questions/47590/what-are-good-initial-weights-in-a-neural-network
+	scale := T(math.Sqrt(float64(nn.NInputs)))
 	for i := 0; i < nn.NInputs; i++ {
 		for j := 0; j < nn.NHiddens; j++ {
-			nn.InputWeights[j][i] = random(-1, 1)
+			nn.InputWeights[j][i] = random[T](nn.rnd, -1, 1) / scale
 		}
 	}
 
+	scale = T(math.Sqrt(float64(nn.NHiddens)))
 	for i := 0; i < nn.NHiddens; i++ {
 		for j := 0; j < nn.NOutputs; j++ {
-			nn.OutputWeights[j][i] = random(-1, 1)
+			nn.OutputWeights[j][i] = random[T](nn.rnd, -1, 1) / scale
 		}
 	}
 
-	nn.InputChanges = matrix(nn.NInputs, nn.NHiddens)
-	nn.OutputChanges = matrix(nn.NHiddens, nn.NOutputs)
-}
-
-/*
-Set the number of contexts to add to the network.
-
-By default the network do not have any context so it is a simple Feed Forward network,
-when contexts are added the network behaves like an Elman's SRN (Simple Recurrent Network).
-
-The first parameter (nContexts) is used to indicate the number of contexts to be used,
-the second parameter (initValues) can be used to create custom initialized contexts.
-
-If 'initValues' is set, the first parameter 'nContexts' is ignored and
-the contexts provided in 'initValues' are used.
-
-When using 'initValues' note that contexts must have the same size of hidden nodes + 1 (bias node).
-*/
-func (nn *FeedForward) SetContexts(nContexts int, initValues [][]float64) {
-	if initValues == nil {
-		initValues = make([][]float64, nContexts)
+	nn.InputChanges = matrix[T](nn.NHiddens, nn.NInputs)
+	nn.OutputChanges = matrix[T](nn.NOutputs, nn.NHiddens)
+	nn.Version = layout
 
-		for i := 0; i < nContexts; i++ {
-			initValues[i] = vector(nn.NHiddens, 0.5)
-		}
-	}
+	nn.Activation = builtin[T]("sigmoid")
+	nn.DActivation = builtin[T]("dsigmoid")
 
-	nn.Contexts = initValues
+	nn.buffers()
 }
 
-/*
-The Update method is used to activate the Neural Network.
-
-Given an array of inputs, it returns an array, of length equivalent of number of outputs, with values ranging from 0 to 1.
-*/
-func (nn *FeedForward) Update(inputs []float64) []float64 {
-	return nn.update(inputs, false)
+// SetRand sets the random source used for initialization, dropout and shuffling, the global source of math/rand is used if nil
+func (nn *Network[T]) SetRand(rnd *rand.Rand) {
+	nn.rnd = rnd
 }
 
-func (nn *FeedForward) update(inputs []float64, train bool) []float64 {
-	if len(inputs) != nn.NInputs-1 {
-		log.Fatal("Error: wrong number of inputs")
-	}
-
-	for i := 0; i < nn.NInputs-1; i++ {
-		nn.InputActivations[i] = inputs[i]
-	}
-
-	for i := 0; i < nn.NHiddens-1; i++ {
-		sum := dot64(nn.InputActivations, nn.InputWeights[i])
-
-		// compute contexts sum
-		for k := 0; k < len(nn.Contexts); k++ {
-			for j := 0; j < nn.NHiddens-1; j++ {
-				sum += nn.Contexts[k][j]
-			}
-		}
-
-		nn.HiddenActivations[i] = sigmoid(sum)
-
-		//http://iamtrask.github.io/2015/07/28/dropout/
-		if train && nn.Dropout != 0 {
-			if rand.Float64() > 1-nn.Dropout {
-				nn.HiddenActivations[i] = 0
-			} else {
-				nn.HiddenActivations[i] *= 1 / (1 - nn.Dropout)
-			}
-		}
-	}
+// Copy returns a deep copy of the Network which can be used concurrently with it, the copy uses the global random source
+func (nn *Network[T]) Copy() *Network[T] {
+	c := *nn
+	c.InputActivations = append([]T(nil), nn.InputActivations...)
+	c.HiddenActivations = append([]T(nil), nn.HiddenActivations...)
+	c.OutputActivations = append([]T(nil), nn.OutputActivations...)
+	c.Contexts = nn.contexts()
+	c.InputWeights, c.OutputWeights = clone(nn.InputWeights), clone(nn.OutputWeights)
+	c.InputChanges, c.OutputChanges = clone(nn.InputChanges), clone(nn.OutputChanges)
+	c.outputDeltas, c.hiddenDeltas, c.inputDeltas, c.gradient, c.order, c.rnd = nil, nil, nil, nil, nil, nil
+	if nn.Layers != nil {
+		c.Layers = make([]Layer[T], len(nn.Layers))
+		for i, layer := range nn.Layers {
+			c.Layers[i] = layer.Copy()
+		}
+	}
+	c.buffers()
+	return &c
+}
 
-	// update the contexts
-	if len(nn.Contexts) > 0 {
-		for i := len(nn.Contexts) - 1; i > 0; i-- {
-			nn.Contexts[i] = nn.Contexts[i-1]
-		}
-		nn.Contexts[0] = nn.HiddenActivations
-	}
+// SetOutput sets the writer Test and debug output is printed to, os.Stdout is used if nil
+func (nn *Network[T]) SetOutput(w io.Writer) {
+	nn.output = w
+}
 
-	if nn.Regression {
-		for i := 0; i < nn.NOutputs; i++ {
-			nn.OutputActivations[i] = dot64(nn.HiddenActivations, nn.OutputWeights[i])
-		}
-	} else {
-		for i := 0; i < nn.NOutputs; i++ {
-			sum := dot64(nn.HiddenActivations, nn.OutputWeights[i])
+// SetLogger sets a structured logger for Test and debug output, it is used instead of the output writer
+func (nn *Network[T]) SetLogger(logger *slog.Logger) {
+	nn.logger = logger
+}
 
-			nn.OutputActivations[i] = sigmoid(sum)
-		}
+func (nn *Network[T]) writer() io.Writer {
+	if nn.output == nil {
+		return os.Stdout
 	}
-
-	return nn.OutputActivations
+	return nn.output
 }
 
-func (nn *FeedForward) UpdateWithNoise(inputs []float64, noise [][]float64) []float64 {
-	if len(inputs) != nn.NInputs-1 {
-		log.Fatal("Error: wrong number of inputs")
-	}
+// layout is the current Version of the layout of the weights and changes
+const layout = 1
 
-	if nn.Regression {
-		for i := 0; i < nn.NInputs-1; i++ {
-			nn.InputActivations[i] = inputs[i] + noise[0][i]
-		}
-	} else {
-		for i := 0; i < nn.NInputs-1; i++ {
-			nn.InputActivations[i] = normalize(inputs[i] + noise[0][i])
-		}
+// migrate converts the changes of a network saved before Version 1 to the current layout
+func (nn *Network[T]) migrate() {
+	if nn.Version >= layout {
+		return
 	}
-
-	for i := 0; i < nn.NHiddens-1; i++ {
-		sum := dot64(nn.InputActivations, nn.InputWeights[i])
-
-		// compute contexts sum
-		for k := 0; k < len(nn.Contexts); k++ {
-			for j := 0; j < nn.NHiddens-1; j++ {
-				sum += nn.Contexts[k][j]
-			}
-		}
-
-		nn.HiddenActivations[i] = normalize(sigmoid(sum) + noise[1][i])
+	if len(nn.InputChanges) == nn.NInputs && nn.NInputs > 0 && len(nn.InputChanges[0]) == nn.NHiddens {
+		nn.InputChanges, _ = transpose(nn.InputChanges)
 	}
-
-	// update the contexts
-	if len(nn.Contexts) > 0 {
-		for i := len(nn.Contexts) - 1; i > 0; i-- {
-			nn.Contexts[i] = nn.Contexts[i-1]
-		}
-		nn.Contexts[0] = nn.HiddenActivations
+	if len(nn.OutputChanges) == nn.NHiddens && nn.NHiddens > 0 && len(nn.OutputChanges[0]) == nn.NOutputs {
+		nn.OutputChanges, _ = transpose(nn.OutputChanges)
 	}
+	nn.Version = layout
+}
 
-	if nn.Regression {
-		for i := 0; i < nn.NOutputs; i++ {
-			sum := dot64(nn.HiddenActivations, nn.OutputWeights[i])
-
-			nn.OutputActivations[i] = sum + noise[2][i]
-		}
-	} else {
-		for i := 0; i < nn.NOutputs; i++ {
-			sum := dot64(nn.HiddenActivations, nn.OutputWeights[i])
-
-			nn.OutputActivations[i] = normalize(sigmoid(sum) + noise[2][i])
-		}
-	}
+// buffers allocates the scratch buffers so that training doesn't allocate
+func (nn *Network[T]) buffers() {
+	nn.migrate()
+	if len(nn.outputDeltas) == nn.NOutputs && len(nn.hiddenDeltas) == nn.NHiddens &&
+		len(nn.inputDeltas) == nn.NInputs-1 && nn.gradient.fits(nn) {
+		return
+	}
+	nn.outputDeltas = make([]T, nn.NOutputs)
+	nn.hiddenDeltas = make([]T, nn.NHiddens)
+	nn.inputDeltas = make([]T, nn.NInputs-1)
+	nn.gradient = nn.NewGradient()
+	nn.noise = [][]T{make([]T, nn.NInputs-1), make([]T, nn.NHiddens-1), make([]T, nn.NOutputs)}
+}
 
-	return nn.OutputActivations
+// FlatInputWeights returns the row major buffer backing InputWeights
+func (nn *Network[T]) FlatInputWeights() []T {
+	return flat(nn.InputWeights)
 }
 
-/*
-The BackPropagate method is used, when training the Neural Network,
-to back propagate the errors from network activation.
-*/
-func (nn *FeedForward) BackPropagate(targets []float64, lRate, mFactor float64) float64 {
-	if len(targets) != nn.NOutputs {
-		log.Fatal("Error: wrong number of target values")
-	}
+// FlatOutputWeights returns the row major buffer backing OutputWeights
+func (nn *Network[T]) FlatOutputWeights() []T {
+	return flat(nn.OutputWeights)
+}
 
-	outputDeltas := vector(nn.NOutputs, 0.0)
-	if nn.Regression {
-		for i := 0; i < nn.NOutputs; i++ {
-			outputDeltas[i] = (targets[i] - nn.OutputActivations[i])
-		}
-	} else {
-		for i := 0; i < nn.NOutputs; i++ {
-			outputDeltas[i] = dsigmoid(nn.OutputActivations[i]) * (targets[i] - nn.OutputActivations[i])
-		}
-	}
+// FlatInputChanges returns the row major buffer backing InputChanges
+func (nn *Network[T]) FlatInputChanges() []T {
+	return flat(nn.InputChanges)
+}
 
-	hiddenDeltas := vector(nn.NHiddens, 0.0)
-	for i := 0; i < nn.NHiddens; i++ {
-		var e float64
+// FlatOutputChanges returns the row major buffer backing OutputChanges
+func (nn *Network[T]) FlatOutputChanges() []T {
+	return flat(nn.OutputChanges)
+}
 
-		for j := 0; j < nn.NOutputs; j++ {
-			e += outputDeltas[j] * nn.OutputWeights[j][i]
+func (nn *Network[T]) SetWeights(weights []T) {
+	w := 0
+	for i := 0; i < nn.NInputs; i++ {
+		for j := 0; j < nn.NHiddens; j++ {
+			nn.InputWeights[j][i] = weights[w]
+			w++
 		}
-
-		hiddenDeltas[i] = dsigmoid(nn.HiddenActivations[i]) * e
 	}
 
-	change := make([]float64, nn.NOutputs)
 	for i := 0; i < nn.NHiddens; i++ {
-		copy(change, outputDeltas)
-		scal64(nn.HiddenActivations[i], change)
-		scal64(mFactor, nn.OutputChanges[i])
-		axpy64(lRate, change, nn.OutputChanges[i])
 		for j := 0; j < nn.NOutputs; j++ {
-			nn.OutputWeights[j][i] = nn.OutputWeights[j][i] + nn.OutputChanges[i][j]
-		}
-		copy(nn.OutputChanges[i], change)
-	}
-
-	change = make([]float64, nn.NHiddens)
-	for i := 0; i < nn.NInputs; i++ {
-		copy(change, hiddenDeltas)
-		scal64(nn.InputActivations[i], change)
-		scal64(mFactor, nn.InputChanges[i])
-		axpy64(lRate, change, nn.InputChanges[i])
-		for j := 0; j < nn.NHiddens; j++ {
-			nn.InputWeights[j][i] = nn.InputWeights[j][i] + nn.InputChanges[i][j]
+			nn.OutputWeights[j][i] = weights[w]
+			w++
 		}
-		copy(nn.InputChanges[i], change)
-	}
-
-	var e float64
-
-	for i := 0; i < len(targets); i++ {
-		e += math.Pow(targets[i]-nn.OutputActivations[i], 2)
 	}
-
-	return e
 }
 
-/*
-This method is used to train the Network, it will run the training operation for 'iterations' times
-and return the computed errors when training.
-*/
-func (nn *FeedForward) Train(patterns [][][]float64, iterations int, lRate, mFactor float64, debug bool) []float64 {
-	errors := make([]float64, iterations)
-
-	for i := 0; i < iterations; i++ {
-		var e float64
-		var n int
-		for _, p := range patterns {
-			nn.update(p[0], true)
-
-			tmp := nn.BackPropagate(p[1], lRate, mFactor)
-			e += tmp
-			n += len(p[1])
-		}
-
-		errors[i] = e / float64(n)
-
-		if debug && i%1000 == 0 {
-			fmt.Println(i, e)
-		}
-	}
-
-	return errors
+func (nn *Network[T]) SetTanhActivation() {
+	nn.Activation = builtin[T]("tanh")
+	nn.DActivation = builtin[T]("dtanh")
 }
 
-func (nn *FeedForward) Test(patterns [][][]float64) {
-	for _, p := range patterns {
-		fmt.Println(p[0], "->", nn.Update(p[0]), " : ", p[1])
-	}
+// SetHiddenActivation sets the activation function of the hidden layer and its derivative
+func (nn *Network[T]) SetHiddenActivation(activation, dactivation Activation[T]) {
+	nn.HiddenActivation = activation
+	nn.DHiddenActivation = dactivation
 }
 
-// FeedForwad struct is used to represent a simple neural network
-type FeedForward32 struct {
-	// Number of input, hidden and output nodes
-	NInputs, NHiddens, NOutputs int
-	// Whether it is regression or not
-	Regression bool
-	// Activations for nodes
-	InputActivations, HiddenActivations, OutputActivations []float32
-	// ElmanRNN contexts
-	Contexts [][]float32
-	// Weights
-	InputWeights, OutputWeights [][]float32
-	// Last change in weights for momentum
-	InputChanges, OutputChanges [][]float32
-	// Set for dropout
-	Dropout float32
-	// Activation function
-	Activation  func(x float32) float32
-	DActivation func(y float32) float32
+// SetOutputActivation sets the activation function of the output layer and its derivative
+func (nn *Network[T]) SetOutputActivation(activation, dactivation Activation[T]) {
+	nn.OutputActivation = activation
+	nn.DOutputActivation = dactivation
 }
 
-type Activation32 func(x float32) float32
-
-type Context32 struct {
-	Iterations     int
-	LRate, MFactor float32
-	Debug          bool
-
-	Activations []Activation32
+// SetReLUHiddenActivation uses rectified linear units for the hidden layer
+func (nn *Network[T]) SetReLUHiddenActivation() {
+	nn.SetHiddenActivation(builtin[T]("relu"), builtin[T]("drelu"))
 }
 
-type Config32 func(context *Context32) *Context32
-
-/*
-Initialize the neural network;
-
-the 'inputs' value is the number of inputs the network will have,
-the 'hiddens' value is the number of hidden nodes and
-the 'outputs' value is the number of the outputs of the network.
-*/
-func (nn *FeedForward32) Init(inputs, hiddens, outputs int) {
-	nn.NInputs = inputs + 1   // +1 for bias
-	nn.NHiddens = hiddens + 1 // +1 for bias
-	nn.NOutputs = outputs
-
-	nn.InputActivations = vector32(nn.NInputs, 1.0)
-	nn.HiddenActivations = vector32(nn.NHiddens, 1.0)
-	nn.OutputActivations = vector32(nn.NOutputs, 1.0)
-
-	nn.InputWeights = matrix32(nn.NHiddens, nn.NInputs)
-	nn.OutputWeights = matrix32(nn.NOutputs, nn.NHiddens)
-
-	// http://stats.stackexchange.com/questions/47590/what-are-good-initial-weights-in-a-neural-network
-	scale := float32(math.Sqrt(float64(nn.NInputs)))
-	for i := 0; i < nn.NInputs; i++ {
-		for j := 0; j < nn.NHiddens; j++ {
-			nn.InputWeights[j][i] = random32(-1, 1) / scale
-		}
-	}
+// activationFuncs returns the activation functions, which Model.Save stores by name
+func (nn *Network[T]) activationFuncs() []*func(x T) T {
+	return []*func(x T) T{&nn.Activation, &nn.DActivation, &nn.HiddenActivation, &nn.DHiddenActivation,
+		&nn.OutputActivation, &nn.DOutputActivation}
+}
 
-	scale = float32(math.Sqrt(float64(nn.NHiddens)))
-	for i := 0; i < nn.NHiddens; i++ {
-		for j := 0; j < nn.NOutputs; j++ {
-			nn.OutputWeights[j][i] = random32(-1, 1) / scale
-		}
+func (nn *Network[T]) activation() (Activation[T], Activation[T]) {
+	if nn.Activation == nil {
+		return sigmoid[T], dsigmoid[T]
 	}
-
-	nn.InputChanges = matrix32(nn.NInputs, nn.NHiddens)
-	nn.OutputChanges = matrix32(nn.NHiddens, nn.NOutputs)
-
-	nn.Activation = sigmoid32
-	nn.DActivation = dsigmoid32
+	return nn.Activation, nn.DActivation
 }
 
-func (nn *FeedForward32) SetWeights(weights []float32) {
-	w := 0
-	for i := 0; i < nn.NInputs; i++ {
-		for j := 0; j < nn.NHiddens; j++ {
-			nn.InputWeights[j][i] = weights[w]
-			w++
-		}
+func (nn *Network[T]) hiddenActivation() (Activation[T], Activation[T]) {
+	activation, dactivation := nn.activation()
+	if nn.HiddenActivation != nil {
+		activation = nn.HiddenActivation
 	}
-
-	for i := 0; i < nn.NHiddens; i++ {
-		for j := 0; j < nn.NOutputs; j++ {
-			nn.OutputWeights[j][i] = weights[w]
-			w++
-		}
+	if nn.DHiddenActivation != nil {
+		dactivation = nn.DHiddenActivation
 	}
+	return activation, dactivation
 }
 
-func (nn *FeedForward32) SetTanhActivation() {
-	nn.Activation = tanh32
-	nn.DActivation = dtanh32
+func (nn *Network[T]) outputActivation() (Activation[T], Activation[T]) {
+	if nn.Regression {
+		return linear[T], dlinear[T]
+	}
+	activation, dactivation := nn.activation()
+	if nn.OutputActivation != nil {
+		activation = nn.OutputActivation
+	}
+	if nn.DOutputActivation != nil {
+		dactivation = nn.DOutputActivation
+	}
+	return activation, dactivation
 }
 
 /*
@@ -411,36 +337,54 @@ the contexts provided in 'initValues' are used.
 
 When using 'initValues' note that contexts must have the same size of hidden nodes + 1 (bias node).
 */
-func (nn *FeedForward32) SetContexts(nContexts int, initValues [][]float32) {
+func (nn *Network[T]) SetContexts(nContexts int, initValues [][]T) {
 	if initValues == nil {
-		initValues = make([][]float32, nContexts)
+		initValues = make([][]T, nContexts)
 
 		for i := 0; i < nContexts; i++ {
-			initValues[i] = vector32(nn.NHiddens, 0.5)
+			initValues[i] = vector[T](nn.NHiddens, 0.5)
 		}
 	}
 
 	nn.Contexts = initValues
 }
 
+// ResetContexts sets the values of all the contexts back to 0.5 in place, without allocating
+func (nn *Network[T]) ResetContexts() {
+	for _, context := range nn.Contexts {
+		for i := range context {
+			context[i] = 0.5
+		}
+	}
+}
+
+// pushContexts shifts the contexts and copies the hidden activations into the first one, the buffer of the
+// oldest context is reused so the contexts never alias the hidden layer and ResetContexts can't overwrite it
+func (nn *Network[T]) pushContexts() {
+	if len(nn.Contexts) == 0 {
+		return
+	}
+	last := nn.Contexts[len(nn.Contexts)-1]
+	for i := len(nn.Contexts) - 1; i > 0; i-- {
+		nn.Contexts[i] = nn.Contexts[i-1]
+	}
+	nn.Contexts[0] = last
+	copy(last, nn.HiddenActivations)
+}
+
 /*
 The Update method is used to activate the Neural Network.
 
 Given an array of inputs, it returns an array, of length equivalent of number of outputs, with values ranging from 0 to 1.
 */
-func (nn *FeedForward32) Update(inputs []float32) []float32 {
-	context := Context32{
-		Activations: []Activation32{nn.Activation, nn.Activation},
-	}
-	if nn.Regression {
-		context.Activations[1] = func(x float32) float32 {
-			return x
-		}
-	}
-	return nn.update(inputs, &context)
+func (nn *Network[T]) Update(inputs []T) []T {
+	hidden, _ := nn.hiddenActivation()
+	output, _ := nn.outputActivation()
+	return nn.update(inputs, hidden, output)
 }
 
-func (nn *FeedForward32) update(inputs []float32, context *Context32) []float32 {
+func (nn *Network[T]) update(inputs []T, hidden, output Activation[T]) []T {
+	inputs = nn.forward(inputs)
 	if len(inputs) != nn.NInputs-1 {
 		log.Fatal("Error: wrong number of inputs")
 	}
@@ -450,7 +394,7 @@ func (nn *FeedForward32) update(inputs []float32, context *Context32) []float32
 	}
 
 	for i := 0; i < nn.NHiddens-1; i++ {
-		sum := dot32(nn.InputActivations, nn.InputWeights[i])
+		sum := dot(nn.InputActivations, nn.InputWeights[i])
 
 		// compute contexts sum
 		for k := 0; k < len(nn.Contexts); k++ {
@@ -459,27 +403,21 @@ func (nn *FeedForward32) update(inputs []float32, context *Context32) []float32
 			}
 		}
 
-		nn.HiddenActivations[i] = context.Activations[0](sum)
+		nn.HiddenActivations[i] = hidden(sum)
 	}
 
-	// update the contexts
-	if len(nn.Contexts) > 0 {
-		for i := len(nn.Contexts) - 1; i > 0; i-- {
-			nn.Contexts[i] = nn.Contexts[i-1]
-		}
-		nn.Contexts[0] = nn.HiddenActivations
-	}
+	nn.pushContexts()
 
 	for i := 0; i < nn.NOutputs; i++ {
-		sum := dot32(nn.HiddenActivations, nn.OutputWeights[i])
+		sum := dot(nn.HiddenActivations, nn.OutputWeights[i])
 
-		nn.OutputActivations[i] = context.Activations[1](sum)
+		nn.OutputActivations[i] = output(sum)
 	}
 
 	return nn.OutputActivations
 }
 
-func (nn *FeedForward32) HalfUpdate(inputs []float32) []float32 {
+func (nn *Network[T]) HalfUpdate(inputs []T) []T {
 	if len(inputs) != nn.NHiddens-1 {
 		log.Fatal("Error: wrong number of inputs")
 	}
@@ -488,144 +426,70 @@ func (nn *FeedForward32) HalfUpdate(inputs []float32) []float32 {
 		nn.HiddenActivations[i] = inputs[i]
 	}
 
-	if nn.Regression {
-		for i := 0; i < nn.NOutputs; i++ {
-			nn.OutputActivations[i] = dot32(nn.HiddenActivations, nn.OutputWeights[i])
-		}
-	} else {
-		for i := 0; i < nn.NOutputs; i++ {
-			sum := dot32(nn.HiddenActivations, nn.OutputWeights[i])
+	output, _ := nn.outputActivation()
+	for i := 0; i < nn.NOutputs; i++ {
+		sum := dot(nn.HiddenActivations, nn.OutputWeights[i])
 
-			nn.OutputActivations[i] = nn.Activation(sum)
-		}
+		nn.OutputActivations[i] = output(sum)
 	}
 
 	return nn.OutputActivations
 }
 
-func (nn *FeedForward32) UpdateWithNoise(inputs []float32, noise [][]float32) []float32 {
-	if len(inputs) != nn.NInputs-1 {
-		log.Fatal("Error: wrong number of inputs")
-	}
-
-	if nn.Regression {
-		for i := 0; i < nn.NInputs-1; i++ {
-			nn.InputActivations[i] = inputs[i] + noise[0][i]
-		}
-	} else {
-		for i := 0; i < nn.NInputs-1; i++ {
-			nn.InputActivations[i] = normalize32(inputs[i] + noise[0][i])
-		}
-	}
-
-	for i := 0; i < nn.NHiddens-1; i++ {
-		sum := dot32(nn.InputActivations, nn.InputWeights[i])
-
-		// compute contexts sum
-		for k := 0; k < len(nn.Contexts); k++ {
-			for j := 0; j < nn.NHiddens-1; j++ {
-				sum += nn.Contexts[k][j]
-			}
-		}
-
-		nn.HiddenActivations[i] = normalize32(nn.Activation(sum) + noise[1][i])
-	}
-
-	// update the contexts
-	if len(nn.Contexts) > 0 {
-		for i := len(nn.Contexts) - 1; i > 0; i-- {
-			nn.Contexts[i] = nn.Contexts[i-1]
-		}
-		nn.Contexts[0] = nn.HiddenActivations
-	}
-
-	if nn.Regression {
-		for i := 0; i < nn.NOutputs; i++ {
-			sum := dot32(nn.HiddenActivations, nn.OutputWeights[i])
-
-			nn.OutputActivations[i] = sum + noise[2][i]
-		}
-	} else {
-		for i := 0; i < nn.NOutputs; i++ {
-			sum := dot32(nn.HiddenActivations, nn.OutputWeights[i])
-
-			nn.OutputActivations[i] = normalize32(nn.Activation(sum) + noise[2][i])
-		}
-	}
+/*
+UpdateWithNoise activates the Network like Update and adds noise[0], noise[1] and noise[2]
+to the inputs, the hidden activations and the outputs.
 
-	return nn.OutputActivations
+The noisy hidden activations are clamped to [0, 1], and so are the noisy inputs and outputs when not Regression.
+*/
+func (nn *Network[T]) UpdateWithNoise(inputs []T, noise [][]T) []T {
+	hidden, _ := nn.hiddenActivation()
+	output, _ := nn.outputActivation()
+	return nn.updateWithNoise(inputs, noise, hidden, output, true)
 }
 
 /*
 The BackPropagate method is used, when training the Neural Network,
 to back propagate the errors from network activation.
-*/
-func (nn *FeedForward32) BackPropagate(targets []float32, lRate, mFactor float32) float32 {
-	if len(targets) != nn.NOutputs {
-		log.Fatal("Error: wrong number of target values")
-	}
-
-	outputDeltas := vector32(nn.NOutputs, 0.0)
-	if nn.Regression {
-		for i := 0; i < nn.NOutputs; i++ {
-			outputDeltas[i] = (targets[i] - nn.OutputActivations[i])
-		}
-	} else {
-		for i := 0; i < nn.NOutputs; i++ {
-			outputDeltas[i] = nn.DActivation(nn.OutputActivations[i]) * (targets[i] - nn.OutputActivations[i])
-		}
-	}
-
-	hiddenDeltas := vector32(nn.NHiddens, 0.0)
-	for i := 0; i < nn.NHiddens; i++ {
-		var e float32
 
-		for j := 0; j < nn.NOutputs; j++ {
-			e += outputDeltas[j] * nn.OutputWeights[j][i]
-		}
-
-		hiddenDeltas[i] = nn.DActivation(nn.HiddenActivations[i]) * e
-	}
+It is ComputeGradient followed by ApplyGradient and returns the squared error.
+*/
+func (nn *Network[T]) BackPropagate(targets []T, lRate, mFactor T) T {
+	nn.buffers()
+	nn.gradient.Zero()
+	e := nn.ComputeGradient(targets, nn.gradient)
+	nn.ApplyGradient(nn.gradient, lRate, mFactor)
+	return e
+}
 
-	change := make([]float32, nn.NOutputs)
-	for i := 0; i < nn.NHiddens; i++ {
-		copy(change, outputDeltas)
-		scal32(nn.HiddenActivations[i], change)
-		scal32(mFactor, nn.OutputChanges[i])
-		axpy32(lRate, change, nn.OutputChanges[i])
-		for j := 0; j < nn.NOutputs; j++ {
-			nn.OutputWeights[j][i] = nn.OutputWeights[j][i] + nn.OutputChanges[i][j]
-		}
-		copy(nn.OutputChanges[i], change)
+// dropout returns the hidden activation function with dropout applied when Dropout is set
+func (nn *Network[T]) dropout() Activation[T] {
+	activation, _ := nn.hiddenActivation()
+	if nn.Dropout == 0 {
+		return activation
 	}
 
-	change = make([]float32, nn.NHiddens)
-	for i := 0; i < nn.NInputs; i++ {
-		copy(change, hiddenDeltas)
-		scal32(nn.InputActivations[i], change)
-		scal32(mFactor, nn.InputChanges[i])
-		axpy32(lRate, change, nn.InputChanges[i])
-		for j := 0; j < nn.NHiddens; j++ {
-			nn.InputWeights[j][i] = nn.InputWeights[j][i] + nn.InputChanges[i][j]
+	//http://iamtrask.github.io/2015/07/28/dropout/
+	return func(x T) T {
+		x = activation(x)
+		if random[T](nn.rnd, 0, 1) > 1-nn.Dropout {
+			x = 0
+		} else {
+			x *= 1 / (1 - nn.Dropout)
 		}
-		copy(nn.InputChanges[i], change)
-	}
-
-	var e float32
-
-	for i := 0; i < len(targets); i++ {
-		e += float32(math.Pow(float64(targets[i]-nn.OutputActivations[i]), 2))
+		return x
 	}
-
-	return e
 }
 
 /*
 This method is used to train the Network, it will run the training operation for 'iterations' times
 and return the computed errors when training.
+
+The patterns are shuffled every epoch with the random source of the Network if Shuffle is set,
+otherwise they are visited in order.
 */
-func (nn *FeedForward32) Train(patterns [][][]float32, iterations int, lRate, mFactor float32, debug bool) []float32 {
-	config := func(context *Context32) *Context32 {
+func (nn *Network[T]) Train(patterns [][][]T, iterations int, lRate, mFactor T, debug bool) []T {
+	config := func(context *Context[T]) *Context[T] {
 		context.Iterations = iterations
 		context.LRate = lRate
 		context.MFactor = mFactor
@@ -635,63 +499,159 @@ func (nn *FeedForward32) Train(patterns [][][]float32, iterations int, lRate, mF
 	return nn.TrainWithConfig(patterns, config)
 }
 
-func (nn *FeedForward32) TrainWithConfig(patterns [][][]float32, config Config32) []float32 {
-	hidden, output := nn.Activation, nn.Activation
+func (nn *Network[T]) TrainWithConfig(patterns [][][]T, config Config[T]) []T {
+	return nn.TrainDataset(Patterns[T](patterns), config)
+}
 
-	//http://iamtrask.github.io/2015/07/28/dropout/
-	if nn.Dropout != 0 {
-		hidden = func(x float32) float32 {
-			x = nn.Activation(x)
-			if rand.Float32() > 1-nn.Dropout {
-				x = 0
-			} else {
-				x *= 1 / (1 - nn.Dropout)
-			}
-			return x
-		}
-	}
+/*
+TrainDataset trains the Network on a dataset using the context built by 'config'.
 
-	if nn.Regression {
-		output = func(x float32) float32 {
-			return x
-		}
-	}
+If the dataset is Sequenced the contexts are reset at the start of every sequence.
+*/
+func (nn *Network[T]) TrainDataset(data Dataset[T], config Config[T]) []T {
+	hidden := nn.dropout()
+	output, _ := nn.outputActivation()
 
 	context := config(
-		&Context32{
+		&Context[T]{
 			Iterations:  10,
 			LRate:       0.6,
 			MFactor:     0.4,
 			Debug:       false,
-			Activations: []Activation32{hidden, output},
+			Shuffle:     nn.Shuffle,
+			Activations: []Activation[T]{hidden, output},
 		},
 	)
 
-	errors := make([]float32, context.Iterations)
+	nn.buffers()
+	return nn.epochs(data, context, func(p Sample[T]) (T, int) {
+		if context.Noise != nil {
+			context.Noise.fill(nn.rnd, nn.noise)
+			nn.updateWithNoise(p.Input, nn.noise, context.Activations[0], context.Activations[1], context.Noise.Clamp)
+		} else {
+			nn.update(p.Input, context.Activations[0], context.Activations[1])
+		}
+
+		if context.OnGradient != nil {
+			nn.gradient.Zero()
+			e := nn.ComputeGradient(p.Target, nn.gradient)
+			context.OnGradient(nn.gradient)
+			nn.ApplyGradient(nn.gradient, context.LRate, context.MFactor)
+			return e, len(p.Target)
+		}
+		return nn.BackPropagate(p.Target, context.LRate, context.MFactor), len(p.Target)
+	})
+}
+
+/*
+epochs is the training loop shared by TrainDataset, which Autoencoder.Train uses, and VAE.Train.
 
+It runs the epochs of the context, shuffling the samples with the random source of the Network when asked to,
+resets the contexts at the start of every sequence of a Sequenced dataset and calls 'step' for every sample.
+'step' trains on a sample and returns its error and the number of values the error is a sum of, the error of an
+epoch is their mean. The callbacks of the context, but OnGradient, and the debug output are handled here.
+*/
+func (nn *Network[T]) epochs(data Dataset[T], context *Context[T], step func(p Sample[T]) (T, int)) []T {
+	sequenced, _ := data.(Sequenced)
+	errors := make([]T, context.Iterations)
+
+	if cap(nn.order) < data.Len() {
+		nn.order = make([]int, data.Len())
+	}
+	order := nn.order[:data.Len()]
+	for j := range order {
+		order[j] = j
+	}
+	// the sequences are shuffled as a whole
+	var sequences [][2]int
+	if context.Shuffle && sequenced != nil {
+		for j := range order {
+			if j == 0 || sequenced.SequenceStart(j) {
+				sequences = append(sequences, [2]int{j, j})
+			}
+			sequences[len(sequences)-1][1] = j + 1
+		}
+	}
+
+	best := T(math.Inf(1))
 	for i := 0; i < context.Iterations; i++ {
-		var e float32
-		var n int
-		for _, p := range patterns {
-			nn.update(p[0], context)
+		if context.OnEpochStart != nil {
+			context.OnEpochStart(i)
+		}
+
+		if context.Shuffle && sequences != nil {
+			shuffle(nn.rnd, len(sequences), func(a, b int) {
+				sequences[a], sequences[b] = sequences[b], sequences[a]
+			})
+			k := 0
+			for _, sequence := range sequences {
+				for j := sequence[0]; j < sequence[1]; j++ {
+					order[k] = j
+					k++
+				}
+			}
+		} else if context.Shuffle {
+			shuffle(nn.rnd, len(order), func(a, b int) {
+				order[a], order[b] = order[b], order[a]
+			})
+		}
 
-			tmp := nn.BackPropagate(p[1], context.LRate, context.MFactor)
+		var e T
+		var n int
+		for k, j := range order {
+			if sequenced != nil && sequenced.SequenceStart(j) {
+				nn.ResetContexts()
+			}
+			tmp, values := step(data.Sample(j))
 			e += tmp
-			n += len(p[1])
+			n += values
+
+			if context.OnBatchEnd != nil {
+				context.OnBatchEnd(i, k, tmp)
+			}
 		}
 
-		errors[i] = e / float32(n)
+		errors[i] = e / T(n)
 
 		if context.Debug && i%1000 == 0 {
-			fmt.Println(i, e)
+			if nn.logger != nil {
+				nn.logger.Info("training", "iteration", i, "error", e)
+			} else {
+				fmt.Fprintln(nn.writer(), i, e)
+			}
+		}
+
+		if errors[i] < best {
+			best = errors[i]
+			if context.OnImprovement != nil {
+				context.OnImprovement(i, errors[i])
+			}
+		}
+		if context.OnEpochEnd != nil && context.OnEpochEnd(i, errors[i]) {
+			return errors[:i+1]
 		}
 	}
 
 	return errors
 }
 
-func (nn *FeedForward32) Test(patterns [][][]float32) {
-	for _, p := range patterns {
-		fmt.Println(p[0], "->", nn.Update(p[0]), " : ", p[1])
+func (nn *Network[T]) Test(patterns [][][]T) {
+	nn.TestDataset(Patterns[T](patterns))
+}
+
+// TestDataset prints the output of the Network next to the target for every sample of a dataset
+func (nn *Network[T]) TestDataset(data Dataset[T]) {
+	sequenced, _ := data.(Sequenced)
+	for i := 0; i < data.Len(); i++ {
+		if sequenced != nil && sequenced.SequenceStart(i) {
+			nn.ResetContexts()
+		}
+		p := data.Sample(i)
+		outputs := nn.Update(p.Input)
+		if nn.logger != nil {
+			nn.logger.Info("test", "input", p.Input, "output", outputs, "target", p.Target)
+		} else {
+			fmt.Fprintln(nn.writer(), p.Input, "->", outputs, " : ", p.Target)
+		}
 	}
 }
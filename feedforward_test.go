@@ -1,7 +1,11 @@
 package gobrain
 
 import (
+	"bytes"
+	"encoding/gob"
+	"log/slog"
 	"math/rand"
+	"strings"
 	"testing"
 )
 
@@ -39,10 +43,10 @@ func ExampleSimpleFeedForward() {
 	ff.Update(inputs)
 
 	// Output:
-	// [0 0] -> [0.05750394570844577]  :  [0]
-	// [0 1] -> [0.9301006350712095]  :  [1]
-	// [1 0] -> [0.9278099662272834]  :  [1]
-	// [1 1] -> [0.09740879532462136]  :  [0]
+	// [0 0] -> [0.05727478828645941]  :  [0]
+	// [0 1] -> [0.9332078807793257]  :  [1]
+	// [1 0] -> [0.9321507963991706]  :  [1]
+	// [1 1] -> [0.08947449053452708]  :  [0]
 }
 
 func ExampleSimpleFeedForwardDropOut() {
@@ -81,10 +85,10 @@ func ExampleSimpleFeedForwardDropOut() {
 	ff.Update(inputs)
 
 	// Output:
-	// [0 0] -> [0.010904292959260731]  :  [0]
-	// [0 1] -> [0.9972511065063475]  :  [1]
-	// [1 0] -> [0.9972423459545571]  :  [1]
-	// [1 1] -> [0.0972657515200182]  :  [0]
+	// [0 0] -> [0.009073810183987157]  :  [0]
+	// [0 1] -> [0.9761048829903125]  :  [1]
+	// [1 0] -> [0.9996367070551958]  :  [1]
+	// [1 1] -> [0.13337981214612205]  :  [0]
 }
 
 func BenchmarkFeedForward(b *testing.B) {
@@ -178,7 +182,7 @@ func ExampleSimpleFeedForwardDropOut32() {
 	ff.Update(inputs)
 
 	// Output:
-	// [0 0] -> [0.009071006]  :  [0]
+	// [0 0] -> [0.009071002]  :  [0]
 	// [0 1] -> [0.9761084]  :  [1]
 	// [1 0] -> [0.999637]  :  [1]
 	// [1 1] -> [0.13337947]  :  [0]
@@ -198,3 +202,317 @@ func BenchmarkFeedForward32(b *testing.B) {
 		ff.Train(patterns, 1000, 0.6, 0.4, false)
 	}
 }
+
+func TestFeedForward32LayerActivations(t *testing.T) {
+	patterns := [][][]float32{
+		{{0, 0}, {0}},
+		{{0, 1}, {1}},
+		{{1, 0}, {1}},
+		{{1, 1}, {0}},
+	}
+
+	ff := &FeedForward32{}
+	ff.SetRand(rand.New(rand.NewSource(0)))
+	ff.Init(2, 4, 1)
+	ff.SetReLUHiddenActivation()
+	ff.Train(patterns, 2000, 0.1, 0.4, false)
+
+	for _, p := range patterns {
+		output := ff.Update(p[0])
+		for _, h := range ff.HiddenActivations {
+			if h < 0 {
+				t.Fatalf("hidden activation %f is not a ReLU output", h)
+			}
+		}
+		if output[0] < 0 || output[0] > 1 {
+			t.Fatalf("output %f is not a sigmoid output", output[0])
+		}
+		if d := output[0] - p[1][0]; d > .2 || d < -.2 {
+			t.Fatalf("%v -> %v want %v", p[0], output, p[1])
+		}
+	}
+}
+
+// xorNetwork returns a network initialized from 'rnd', the global random source if nil, and the XOR patterns
+func xorNetwork[T Float](dropout T, rnd *rand.Rand) (*Network[T], [][][]T) {
+	patterns := [][][]T{
+		{{0, 0}, {0}},
+		{{0, 1}, {1}},
+		{{1, 0}, {1}},
+		{{1, 1}, {0}},
+	}
+	nn := &Network[T]{Dropout: dropout}
+	nn.SetRand(rnd)
+	nn.Init(2, 4, 1)
+	return nn, patterns
+}
+
+func benchmarkUpdate[T Float](b *testing.B) {
+	nn, patterns := xorNetwork[T](0, rand.New(rand.NewSource(1)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		nn.Update(patterns[n%len(patterns)][0])
+	}
+}
+
+func BenchmarkUpdate(b *testing.B) {
+	benchmarkUpdate[float64](b)
+}
+
+func BenchmarkUpdate32(b *testing.B) {
+	benchmarkUpdate[float32](b)
+}
+
+func benchmarkBackPropagate[T Float](b *testing.B) {
+	nn, patterns := xorNetwork[T](0, rand.New(rand.NewSource(1)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		p := patterns[n%len(patterns)]
+		nn.Update(p[0])
+		nn.BackPropagate(p[1], 0.6, 0.4)
+	}
+}
+
+func BenchmarkBackPropagate(b *testing.B) {
+	benchmarkBackPropagate[float64](b)
+}
+
+func BenchmarkBackPropagate32(b *testing.B) {
+	benchmarkBackPropagate[float32](b)
+}
+
+// benchmarkTrain runs b.N epochs in a single call so the allocations reported are per epoch
+func benchmarkTrain[T Float](b *testing.B) {
+	nn, patterns := xorNetwork[T](.2, rand.New(rand.NewSource(1)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	nn.Train(patterns, b.N, 0.6, 0.4, false)
+}
+
+func BenchmarkTrain(b *testing.B) {
+	benchmarkTrain[float64](b)
+}
+
+func BenchmarkTrain32(b *testing.B) {
+	benchmarkTrain[float32](b)
+}
+
+func TestTrainingAllocations(t *testing.T) {
+	nn, patterns := xorNetwork[float32](.2, rand.New(rand.NewSource(1)))
+	if allocs := testing.AllocsPerRun(100, func() {
+		nn.Update(patterns[0][0])
+		nn.BackPropagate(patterns[0][1], 0.6, 0.4)
+	}); allocs != 0 {
+		t.Fatalf("Update and BackPropagate allocate %v times", allocs)
+	}
+
+	once := testing.AllocsPerRun(10, func() {
+		nn.Train(patterns, 1, 0.6, 0.4, false)
+	})
+	many := testing.AllocsPerRun(10, func() {
+		nn.Train(patterns, 100, 0.6, 0.4, false)
+	})
+	if once != many {
+		t.Fatalf("Train allocates per epoch: %v allocations for 1 epoch, %v for 100", once, many)
+	}
+
+	shuffled := func(iterations int) Config[float32] {
+		return func(context *Context[float32]) *Context[float32] {
+			context.Iterations, context.Shuffle = iterations, true
+			return context
+		}
+	}
+	once = testing.AllocsPerRun(10, func() {
+		nn.TrainWithConfig(patterns, shuffled(1))
+	})
+	many = testing.AllocsPerRun(10, func() {
+		nn.TrainWithConfig(patterns, shuffled(100))
+	})
+	if once != many {
+		t.Fatalf("shuffling allocates per epoch: %v allocations for 1 epoch, %v for 100", once, many)
+	}
+
+	nn.SetContexts(1, nil)
+	samples := make([]Sample[float32], len(patterns))
+	for i := range samples {
+		samples[i] = Patterns[float32](patterns).Sample(i)
+	}
+	sequences := NewSequences([][]Sample[float32]{samples, samples})
+	sequenced := func(iterations int) Config[float32] {
+		return func(context *Context[float32]) *Context[float32] {
+			context.Iterations = iterations
+			return context
+		}
+	}
+	once = testing.AllocsPerRun(10, func() {
+		nn.TrainDataset(sequences, sequenced(1))
+	})
+	many = testing.AllocsPerRun(10, func() {
+		nn.TrainDataset(sequences, sequenced(100))
+	})
+	if once != many {
+		t.Fatalf("resetting the contexts allocates per epoch: %v allocations for 1 epoch, %v for 100", once, many)
+	}
+}
+
+func TestFlatWeights(t *testing.T) {
+	nn, _ := xorNetwork[float64](0, rand.New(rand.NewSource(1)))
+	weights := nn.FlatInputWeights()
+	if len(weights) != nn.NHiddens*nn.NInputs {
+		t.Fatalf("got %d weights, want %d", len(weights), nn.NHiddens*nn.NInputs)
+	}
+	weights[nn.NInputs+1] = 42
+	if nn.InputWeights[1][1] != 42 {
+		t.Fatal("FlatInputWeights is not backing InputWeights")
+	}
+	if len(nn.FlatInputChanges()) != len(weights) || len(nn.FlatOutputChanges()) != len(nn.FlatOutputWeights()) {
+		t.Fatal("changes are not in the same orientation as the weights")
+	}
+
+	nn.OutputWeights[0] = []float64{1, 2, 3, 4, 5}
+	weights = nn.FlatOutputWeights()
+	weights[2] = 42
+	if nn.OutputWeights[0][2] != 42 {
+		t.Fatal("rows were not repacked into a contiguous buffer")
+	}
+}
+
+func TestTrainShuffle(t *testing.T) {
+	train := func(shuffle bool) []float64 {
+		nn, patterns := xorNetwork[float64](0, rand.New(rand.NewSource(1)))
+		nn.Shuffle = shuffle
+		nn.Train(patterns, 10, 0.6, 0.4, false)
+		return nn.FlatOutputWeights()
+	}
+	ordered, shuffled, again := train(false), train(true), train(true)
+	if shuffled[0] == ordered[0] {
+		t.Fatal("the patterns were not shuffled")
+	}
+	if shuffled[0] != again[0] {
+		t.Fatalf("got %v and %v with the same random source", shuffled[0], again[0])
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	nn, patterns := xorNetwork[float64](0, rand.New(rand.NewSource(1)))
+	nn.Train(patterns, 10, 0.6, 0.4, false)
+
+	old := nn.Copy()
+	old.Version = 0
+	old.InputChanges, _ = transpose(old.InputChanges)
+	old.OutputChanges, _ = transpose(old.OutputChanges)
+	buffer := &bytes.Buffer{}
+	if err := gob.NewEncoder(buffer).Encode(old); err != nil {
+		t.Fatal(err)
+	}
+	loaded := &Network[float64]{}
+	if err := gob.NewDecoder(buffer).Decode(loaded); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range patterns {
+		nn.Update(p[0])
+		nn.BackPropagate(p[1], 0.6, 0.4)
+		loaded.Update(p[0])
+		loaded.BackPropagate(p[1], 0.6, 0.4)
+	}
+	if loaded.Version != nn.Version {
+		t.Fatalf("version %d after training, want %d", loaded.Version, nn.Version)
+	}
+	for i, w := range loaded.FlatInputWeights() {
+		if w != nn.FlatInputWeights()[i] {
+			t.Fatalf("input weight %d is %v, want %v", i, w, nn.FlatInputWeights()[i])
+		}
+	}
+	for i, w := range loaded.FlatOutputWeights() {
+		if w != nn.FlatOutputWeights()[i] {
+			t.Fatalf("output weight %d is %v, want %v", i, w, nn.FlatOutputWeights()[i])
+		}
+	}
+}
+
+func TestGob(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	nn := &FeedForward{}
+	nn.SetRand(rnd)
+	nn.Init(2, 3, 2)
+	nn.SetTanhActivation()
+	nn.SetReLUHiddenActivation()
+
+	buffer := &bytes.Buffer{}
+	if err := gob.NewEncoder(buffer).Encode(nn); err != nil {
+		t.Fatal(err)
+	}
+	loaded := &FeedForward{}
+	if err := gob.NewDecoder(buffer).Decode(loaded); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		inputs := []float64{rnd.Float64()*2 - 1, rnd.Float64()*2 - 1}
+		a := append([]float64{}, nn.Update(inputs)...)
+		if b := loaded.Update(inputs); a[0] != b[0] || a[1] != b[1] {
+			t.Fatalf("%v -> %v, loaded network %v", inputs, a, b)
+		}
+	}
+
+	nn.HiddenActivation = func(x float64) float64 { return x }
+	if err := gob.NewEncoder(&bytes.Buffer{}).Encode(nn); err == nil {
+		t.Fatal("a custom activation function was encoded")
+	}
+}
+
+func TestCallbacks(t *testing.T) {
+	nn, patterns := xorNetwork[float64](0, rand.New(rand.NewSource(1)))
+	var starts, batches, improvements int
+	errors := nn.TrainWithConfig(patterns, func(context *Context[float64]) *Context[float64] {
+		context.Iterations = 100
+		context.OnEpochStart = func(epoch int) {
+			if epoch != starts {
+				t.Fatalf("epoch %d started, want %d", epoch, starts)
+			}
+			starts++
+		}
+		context.OnBatchEnd = func(epoch, sample int, err float64) {
+			batches++
+		}
+		context.OnImprovement = func(epoch int, err float64) {
+			improvements++
+		}
+		context.OnEpochEnd = func(epoch int, err float64) bool {
+			return epoch == 9
+		}
+		return context
+	})
+	if len(errors) != 10 || starts != 10 {
+		t.Fatalf("training ran %d epochs with %d errors, want 10", starts, len(errors))
+	}
+	if batches != 10*len(patterns) {
+		t.Fatalf("got %d batches, want %d", batches, 10*len(patterns))
+	}
+	if improvements == 0 || improvements > 10 {
+		t.Fatalf("got %d improvements", improvements)
+	}
+}
+
+func TestOutput(t *testing.T) {
+	nn, patterns := xorNetwork[float64](0, rand.New(rand.NewSource(1)))
+	buffer := &bytes.Buffer{}
+	nn.SetOutput(buffer)
+	nn.Test(patterns)
+	if lines := strings.Count(buffer.String(), "\n"); lines != len(patterns) {
+		t.Fatalf("got %d lines of output, want %d", lines, len(patterns))
+	}
+
+	buffer.Reset()
+	nn.SetLogger(slog.New(slog.NewTextHandler(buffer, nil)))
+	nn.TrainWithConfig(patterns, func(context *Context[float64]) *Context[float64] {
+		context.Iterations = 1
+		context.Debug = true
+		return context
+	})
+	if !strings.Contains(buffer.String(), "msg=training iteration=0") {
+		t.Fatalf("unexpected log %q", buffer.String())
+	}
+}
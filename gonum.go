@@ -0,0 +1,41 @@
+package gobrain
+
+import (
+	"gonum.org/v1/gonum/blas/blas32"
+	"gonum.org/v1/gonum/blas/blas64"
+)
+
+// GonumBackend uses the blas64 and blas32 implementations from gonum
+type GonumBackend struct{}
+
+func init() {
+	RegisterBackend(GonumBackend{})
+}
+
+func (GonumBackend) Name() string {
+	return "gonum"
+}
+
+func (GonumBackend) Dot64(X, Y []float64) float64 {
+	return blas64.Implementation().Ddot(len(X), X, 1, Y, 1)
+}
+
+func (GonumBackend) Dot32(X, Y []float32) float32 {
+	return blas32.Implementation().Sdot(len(X), X, 1, Y, 1)
+}
+
+func (GonumBackend) Scal64(alpha float64, X []float64) {
+	blas64.Implementation().Dscal(len(X), alpha, X, 1)
+}
+
+func (GonumBackend) Scal32(alpha float32, X []float32) {
+	blas32.Implementation().Sscal(len(X), alpha, X, 1)
+}
+
+func (GonumBackend) Axpy64(alpha float64, X []float64, Y []float64) {
+	blas64.Implementation().Daxpy(len(X), alpha, X, 1, Y, 1)
+}
+
+func (GonumBackend) Axpy32(alpha float32, X []float32, Y []float32) {
+	blas32.Implementation().Saxpy(len(X), alpha, X, 1, Y, 1)
+}
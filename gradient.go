@@ -0,0 +1,290 @@
+package gobrain
+
+import (
+	"log"
+	"math"
+)
+
+// Gradient holds the gradients of the squared error with respect to the weights of a Network, in the same orientation as the weights
+type Gradient[T Float] struct {
+	Input, Output [][]T
+	// Gradients of the weights of the Layers, nil for the layers without weights
+	Layers [][][]T
+}
+
+// NewGradient allocates a zero gradient for the weights of the Network and of its Layers
+func (nn *Network[T]) NewGradient() *Gradient[T] {
+	gradient := &Gradient[T]{
+		Input:  matrix[T](nn.NHiddens, nn.NInputs),
+		Output: matrix[T](nn.NOutputs, nn.NHiddens),
+	}
+	if nn.Layers != nil {
+		gradient.Layers = make([][][]T, len(nn.Layers))
+		for i, layer := range nn.Layers {
+			gradient.Layers[i] = layer.NewGradient()
+		}
+	}
+	return gradient
+}
+
+func (g *Gradient[T]) fits(nn *Network[T]) bool {
+	return g != nil && len(g.Input) == nn.NHiddens && len(g.Output) == nn.NOutputs && len(g.Layers) == len(nn.Layers) &&
+		(nn.NHiddens == 0 || len(g.Input[0]) == nn.NInputs) && (nn.NOutputs == 0 || len(g.Output[0]) == nn.NHiddens)
+}
+
+// rows calls f with every row of the gradients
+func (g *Gradient[T]) rows(f func(row []T)) {
+	for _, rows := range [2][][]T{g.Input, g.Output} {
+		for _, row := range rows {
+			f(row)
+		}
+	}
+	for _, rows := range g.Layers {
+		for _, row := range rows {
+			f(row)
+		}
+	}
+}
+
+// Zero sets all the gradients to zero
+func (g *Gradient[T]) Zero() {
+	g.rows(func(row []T) {
+		for i := range row {
+			row[i] = 0
+		}
+	})
+}
+
+// Scale multiplies all the gradients by alpha, use 1/n to average a sum of n gradients
+func (g *Gradient[T]) Scale(alpha T) {
+	g.rows(func(row []T) {
+		scal(alpha, row)
+	})
+}
+
+// Add adds the gradients of 'other' which must have the same shape
+func (g *Gradient[T]) Add(other *Gradient[T]) {
+	for i, row := range g.Input {
+		axpy(1, other.Input[i], row)
+	}
+	for i, row := range g.Output {
+		axpy(1, other.Output[i], row)
+	}
+	for i, rows := range g.Layers {
+		for j, row := range rows {
+			axpy(1, other.Layers[i][j], row)
+		}
+	}
+}
+
+/*
+ComputeGradient adds the gradients of the squared error of the last activation of the Network for 'targets'
+to 'gradient', without changing the weights, and returns the squared error.
+
+The gradients are accumulated so a batch is computed by calling Update and ComputeGradient for every sample.
+The gradients of the weights of the Layers are accumulated in gradient.Layers, if it was allocated by NewGradient.
+*/
+func (nn *Network[T]) ComputeGradient(targets []T, gradient *Gradient[T]) T {
+	if len(targets) != nn.NOutputs {
+		log.Fatal("Error: wrong number of target values")
+	}
+
+	_, dhidden := nn.hiddenActivation()
+	_, doutput := nn.outputActivation()
+
+	nn.buffers()
+
+	outputDeltas := nn.outputDeltas
+	if nn.Regression {
+		for i := 0; i < nn.NOutputs; i++ {
+			outputDeltas[i] = (targets[i] - nn.OutputActivations[i])
+		}
+	} else {
+		for i := 0; i < nn.NOutputs; i++ {
+			outputDeltas[i] = doutput(nn.OutputActivations[i]) * (targets[i] - nn.OutputActivations[i])
+		}
+	}
+
+	hiddenDeltas := nn.hiddenDeltas
+	for i := 0; i < nn.NHiddens; i++ {
+		var e T
+
+		for j := 0; j < nn.NOutputs; j++ {
+			e += outputDeltas[j] * nn.OutputWeights[j][i]
+		}
+
+		hiddenDeltas[i] = dhidden(nn.HiddenActivations[i]) * e
+	}
+
+	for i := 0; i < nn.NOutputs; i++ {
+		axpy(-outputDeltas[i], nn.HiddenActivations, gradient.Output[i])
+	}
+
+	// the bias node of the hidden layer has no incoming weights
+	for i := 0; i < nn.NHiddens-1; i++ {
+		axpy(-hiddenDeltas[i], nn.InputActivations, gradient.Input[i])
+	}
+
+	if len(nn.Layers) > 0 {
+		nn.lossInputGradient(nn.inputDeltas)
+		nn.backward(nn.inputDeltas, gradient.Layers)
+	}
+
+	var e T
+
+	for i := 0; i < len(targets); i++ {
+		e += T(math.Pow(float64(targets[i]-nn.OutputActivations[i]), 2))
+	}
+
+	return e
+}
+
+// lossInputGradient sets 'gradient' to the derivatives of the squared error with respect to the inputs, after ComputeGradient
+func (nn *Network[T]) lossInputGradient(gradient []T) {
+	for i := range gradient {
+		gradient[i] = 0
+	}
+	for j := 0; j < nn.NHiddens-1; j++ {
+		axpy(-nn.hiddenDeltas[j], nn.InputWeights[j][:len(gradient)], gradient)
+	}
+}
+
+/*
+BatchGradient adds the gradients of every sample of a dataset to 'gradient' and returns the sum of the squared errors.
+
+The contexts are reset at the start of every sequence of a Sequenced dataset. Dropout is not applied.
+*/
+func (nn *Network[T]) BatchGradient(data Dataset[T], gradient *Gradient[T]) T {
+	sequenced, _ := data.(Sequenced)
+	var e T
+	for i := 0; i < data.Len(); i++ {
+		if sequenced != nil && sequenced.SequenceStart(i) {
+			nn.ResetContexts()
+		}
+		p := data.Sample(i)
+		nn.Update(p.Input)
+		e += nn.ComputeGradient(p.Target, gradient)
+	}
+	return e
+}
+
+func applyGradient[T Float](weights, changes, gradients [][]T, lRate, mFactor T) {
+	for i := range weights {
+		scal(mFactor, changes[i])
+		axpy(-lRate, gradients[i], changes[i])
+		axpy(1, changes[i], weights[i])
+		// the last change is stored without the learning rate and momentum
+		copy(changes[i], gradients[i])
+		scal(-1, changes[i])
+	}
+}
+
+// ApplyGradient takes a gradient descent step with learning rate 'lRate' and momentum 'mFactor', like BackPropagate
+func (nn *Network[T]) ApplyGradient(gradient *Gradient[T], lRate, mFactor T) {
+	applyGradient(nn.OutputWeights, nn.OutputChanges, gradient.Output, lRate, mFactor)
+	applyGradient(nn.InputWeights, nn.InputChanges, gradient.Input, lRate, mFactor)
+	for i, layer := range nn.Layers {
+		if i < len(gradient.Layers) {
+			layer.Apply(gradient.Layers[i], lRate, mFactor)
+		}
+	}
+}
+
+// GradientCheck holds the maximum relative error between the gradients computed by backpropagation
+// and the numerical gradients of every layer
+type GradientCheck struct {
+	// Errors of the input to hidden and hidden to output weights
+	InputError, OutputError float64
+}
+
+// Max returns the maximum relative error of all the layers
+func (g GradientCheck) Max() float64 {
+	return math.Max(g.InputError, g.OutputError)
+}
+
+// relativeError is the error between two gradients relative to the largest one, zero if both are zero
+func relativeError(analytic, numerical float64) float64 {
+	scale := math.Max(math.Abs(analytic), math.Abs(numerical))
+	if scale == 0 {
+		return 0
+	}
+	return math.Abs(analytic-numerical) / scale
+}
+
+// contexts returns a deep copy of the contexts, update stores the hidden activations in the first one
+func (nn *Network[T]) contexts() [][]T {
+	if nn.Contexts == nil {
+		return nil
+	}
+	contexts := make([][]T, len(nn.Contexts))
+	for i, context := range nn.Contexts {
+		contexts[i] = append([]T{}, context...)
+	}
+	return contexts
+}
+
+// restoreContexts sets the contexts to a copy of 'contexts'
+func (nn *Network[T]) restoreContexts(contexts [][]T) {
+	nn.Contexts = nil
+	if contexts != nil {
+		nn.Contexts = make([][]T, len(contexts))
+		for i, context := range contexts {
+			nn.Contexts[i] = append([]T{}, context...)
+		}
+	}
+}
+
+/*
+CheckGradients compares the gradients computed by ComputeGradient, which BackPropagate applies, for a sample
+with the central finite differences (E(w+epsilon) - E(w-epsilon)) / 2epsilon of the squared error
+E = sum((target - output)^2) / 2 for every weight, and reports the maximum relative error per layer.
+
+The contexts are treated as constant inputs. The weights and contexts of the network are left unchanged.
+Only the dense layers are checked.
+A perturbation which moves a ReLU across zero gives a spurious error, a smaller epsilon avoids it.
+*/
+func (nn *Network[T]) CheckGradients(inputs, targets []T, epsilon T) GradientCheck {
+	contexts := nn.contexts()
+	restore := func() {
+		nn.restoreContexts(contexts)
+	}
+	loss := func() float64 {
+		restore()
+		outputs := nn.Update(inputs)
+		var e float64
+		for i, target := range targets {
+			d := float64(target - outputs[i])
+			e += d * d / 2
+		}
+		return e
+	}
+
+	restore()
+	nn.Update(inputs)
+	gradient := nn.NewGradient()
+	nn.ComputeGradient(targets, gradient)
+
+	check := func(weights, gradients [][]T) float64 {
+		var max float64
+		for i, row := range weights {
+			for j, w := range row {
+				row[j] = w + epsilon
+				plus := loss()
+				row[j] = w - epsilon
+				minus := loss()
+				row[j] = w
+				numerical := (plus - minus) / (2 * float64(epsilon))
+				if e := relativeError(float64(gradients[i][j]), numerical); e > max {
+					max = e
+				}
+			}
+		}
+		return max
+	}
+	result := GradientCheck{
+		InputError:  check(nn.InputWeights, gradient.Input),
+		OutputError: check(nn.OutputWeights, gradient.Output),
+	}
+	restore()
+	return result
+}
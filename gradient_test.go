@@ -0,0 +1,128 @@
+package gobrain
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func checkGradients[T Float](t *testing.T, epsilon T, tolerance float64) {
+	networks := []struct {
+		name  string
+		setup func(nn *Network[T])
+	}{
+		{"sigmoid", func(nn *Network[T]) {}},
+		{"tanh", func(nn *Network[T]) { nn.SetTanhActivation() }},
+		{"relu", func(nn *Network[T]) { nn.SetReLUHiddenActivation() }},
+		{"regression", func(nn *Network[T]) { nn.Regression = true }},
+		{"contexts", func(nn *Network[T]) { nn.SetContexts(1, nil) }},
+	}
+	for _, network := range networks {
+		nn := &Network[T]{}
+		nn.SetRand(rand.New(rand.NewSource(1)))
+		nn.Init(3, 4, 2)
+		network.setup(nn)
+		weights := append([]T{}, nn.FlatInputWeights()...)
+
+		for _, sample := range [][][]T{
+			{{0.1, 0.9, 0.4}, {1, 0}},
+			{{0.7, 0.2, 0.9}, {0, 1}},
+		} {
+			check := nn.CheckGradients(sample[0], sample[1], epsilon)
+			if check.InputError > tolerance || check.OutputError > tolerance {
+				t.Errorf("%s: relative errors %g and %g, want at most %g",
+					network.name, check.InputError, check.OutputError, tolerance)
+			}
+		}
+
+		for i, w := range nn.FlatInputWeights() {
+			if w != weights[i] {
+				t.Fatalf("%s: weight %d changed from %v to %v", network.name, i, weights[i], w)
+			}
+		}
+	}
+}
+
+func TestCheckGradients(t *testing.T) {
+	checkGradients[float64](t, 1e-5, 1e-5)
+}
+
+func TestCheckGradients32(t *testing.T) {
+	checkGradients[float32](t, 1e-2, 5e-2)
+}
+
+func TestRelativeError(t *testing.T) {
+	if e := relativeError(0, 0); e != 0 {
+		t.Fatalf("got %v for zero gradients", e)
+	}
+	if e := relativeError(1, 0.9); !closeTo(e, 0.1, 1e-12) {
+		t.Fatalf("got %v, want 0.1", e)
+	}
+}
+
+func TestApplyGradient(t *testing.T) {
+	a, patterns := xorNetwork[float64](0, rand.New(rand.NewSource(1)))
+	b := &Network[float64]{}
+	b.Init(2, 4, 1)
+	copy(b.FlatInputWeights(), a.FlatInputWeights())
+	copy(b.FlatOutputWeights(), a.FlatOutputWeights())
+
+	gradient := b.NewGradient()
+	for i := 0; i < 20; i++ {
+		p := patterns[i%len(patterns)]
+		a.Update(p[0])
+		ea := a.BackPropagate(p[1], 0.6, 0.4)
+
+		b.Update(p[0])
+		gradient.Zero()
+		eb := b.ComputeGradient(p[1], gradient)
+		b.ApplyGradient(gradient, 0.6, 0.4)
+		if ea != eb {
+			t.Fatalf("step %d: errors %v and %v differ", i, ea, eb)
+		}
+	}
+	for i, w := range b.FlatInputWeights() {
+		if w != a.FlatInputWeights()[i] {
+			t.Fatalf("input weight %d is %v, want %v", i, w, a.FlatInputWeights()[i])
+		}
+	}
+	for i, w := range b.FlatOutputWeights() {
+		if w != a.FlatOutputWeights()[i] {
+			t.Fatalf("output weight %d is %v, want %v", i, w, a.FlatOutputWeights()[i])
+		}
+	}
+}
+
+func TestBatchGradient(t *testing.T) {
+	nn, patterns := xorNetwork[float64](0, rand.New(rand.NewSource(1)))
+	weights := append([]float64{}, nn.FlatOutputWeights()...)
+
+	batch := nn.NewGradient()
+	e := nn.BatchGradient(Patterns[float64](patterns), batch)
+	batch.Scale(1 / float64(len(patterns)))
+
+	sum, single := nn.NewGradient(), nn.NewGradient()
+	var total float64
+	for _, p := range patterns {
+		single.Zero()
+		nn.Update(p[0])
+		total += nn.ComputeGradient(p[1], single)
+		sum.Add(single)
+	}
+	sum.Scale(1 / float64(len(patterns)))
+
+	if !closeTo(e, total, 1e-12) {
+		t.Fatalf("batch error %v, want %v", e, total)
+	}
+	for i, row := range batch.Input {
+		for j, g := range row {
+			if !closeTo(g, sum.Input[i][j], 1e-12) {
+				t.Fatalf("input gradient %d %d is %v, want %v", i, j, g, sum.Input[i][j])
+			}
+		}
+	}
+	for i, w := range nn.FlatOutputWeights() {
+		if w != weights[i] {
+			t.Fatalf("output weight %d changed", i)
+		}
+	}
+}
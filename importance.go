@@ -0,0 +1,163 @@
+package gobrain
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sync"
+)
+
+// Predictor is a model which computes its outputs from inputs, like Network and RNN32
+type Predictor[T Float] interface {
+	Update(inputs []T) []T
+}
+
+// Importance configures PermutationImportance
+type Importance[T Float] struct {
+	// Number of permutations of every feature, 5 if not set
+	Repeats int
+	// Metric computed by EvaluateOutputs, "mse" if not set, the metrics like accuracy are maximized and the errors minimized
+	Metric string
+	// Whether the model is a regression, which selects the metrics
+	Regression bool
+	// Number of permutations evaluated in parallel, GOMAXPROCS if not set
+	Workers int
+	// Random source of the permutations, the global source of math/rand if nil
+	Rand *rand.Rand
+}
+
+// FeatureImportance holds the degradation of the metric when an input is permuted, positive when the metric gets worse
+type FeatureImportance struct {
+	Feature        int
+	Mean, Variance float64
+	Scores         []float64
+}
+
+// ImportanceResult holds the importance of every input
+type ImportanceResult struct {
+	// Metric of the model on the data which is not permuted
+	Baseline float64
+	Features []FeatureImportance
+}
+
+func (c Importance[T]) metric() string {
+	if c.Metric == "" {
+		return "mse"
+	}
+	return c.Metric
+}
+
+func (c Importance[T]) score(model Predictor[T], inputs, targets [][]T) float64 {
+	outputs := make([][]T, len(inputs))
+	for i, input := range inputs {
+		outputs[i] = append([]T(nil), model.Update(input)...)
+	}
+	return EvaluateOutputs(outputs, targets, c.Regression).Map()[c.metric()]
+}
+
+/*
+PermutationImportance measures the importance of every input of a model as the degradation of a metric
+when the values of the input are shuffled between the samples of a dataset.
+
+The models are created by 'factory' once per worker since Update is not safe for concurrent use,
+for a trained Network the factory can return nn.Copy(). The samples are evaluated in order and independently.
+*/
+func PermutationImportance[T Float](factory func() Predictor[T], data Dataset[T], config Importance[T]) (*ImportanceResult, error) {
+	if data.Len() < 2 {
+		return nil, fmt.Errorf("gobrain: at least 2 samples are needed, got %d", data.Len())
+	}
+	if _, ok := EvaluateOutputs([][]T{{0}}, [][]T{{0}}, config.Regression).Map()[config.metric()]; !ok {
+		return nil, fmt.Errorf("gobrain: unknown metric %q", config.metric())
+	}
+	maximize := maximized(config.metric())
+	repeats := config.Repeats
+	if repeats <= 0 {
+		repeats = 5
+	}
+	workers := config.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	inputs, targets := make([][]T, data.Len()), make([][]T, data.Len())
+	for i := range inputs {
+		sample := data.Sample(i)
+		inputs[i], targets[i] = sample.Input, sample.Target
+	}
+	features := len(inputs[0])
+
+	result := &ImportanceResult{
+		Baseline: config.score(factory(), inputs, targets),
+		Features: make([]FeatureImportance, features),
+	}
+
+	for feature := range result.Features {
+		result.Features[feature] = FeatureImportance{Feature: feature, Scores: make([]float64, repeats)}
+	}
+
+	// the seeds are drawn up front so the result does not depend on the scheduling
+	type job struct {
+		feature, repeat int
+		seed            int64
+	}
+	jobs := make(chan job)
+	go func() {
+		for feature := 0; feature < features; feature++ {
+			for repeat := 0; repeat < repeats; repeat++ {
+				seed := rand.Int63()
+				if config.Rand != nil {
+					seed = config.Rand.Int63()
+				}
+				jobs <- job{feature: feature, repeat: repeat, seed: seed}
+			}
+		}
+		close(jobs)
+	}()
+
+	var wait sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			model := factory()
+			permuted := make([][]T, len(inputs))
+			for i, input := range inputs {
+				permuted[i] = append([]T(nil), input...)
+			}
+			for job := range jobs {
+				rnd := rand.New(rand.NewSource(job.seed))
+				order := rnd.Perm(len(inputs))
+				for i, j := range order {
+					permuted[i][job.feature] = inputs[j][job.feature]
+				}
+				score := config.score(model, permuted, targets)
+				for i, input := range inputs {
+					permuted[i][job.feature] = input[job.feature]
+				}
+				if maximize {
+					score = result.Baseline - score
+				} else {
+					score -= result.Baseline
+				}
+				result.Features[job.feature].Scores[job.repeat] = score
+			}
+		}()
+	}
+	wait.Wait()
+
+	for i := range result.Features {
+		feature := &result.Features[i]
+		var sum, squares float64
+		for _, score := range feature.Scores {
+			sum += score
+		}
+		feature.Mean = sum / float64(repeats)
+		for _, score := range feature.Scores {
+			squares += (score - feature.Mean) * (score - feature.Mean)
+		}
+		if repeats > 1 {
+			feature.Variance = squares / float64(repeats-1)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,86 @@
+package gobrain
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestPermutationImportance(t *testing.T) {
+	// the target only depends on the first input
+	rnd := rand.New(rand.NewSource(1))
+	data := make(Samples[float64], 200)
+	for i := range data {
+		x, y := rnd.Float64(), rnd.Float64()
+		data[i] = Sample[float64]{Input: []float64{x, y}, Target: []float64{2*x - 1}}
+	}
+	nn := &Network[float64]{Regression: true}
+	nn.SetRand(rand.New(rand.NewSource(1)))
+	nn.Init(2, 4, 1)
+	nn.TrainDataset(data, func(context *Context[float64]) *Context[float64] {
+		context.Iterations = 100
+		context.LRate, context.MFactor = 0.05, 0.1
+		return context
+	})
+
+	results := make([]*ImportanceResult, 2)
+	for i, workers := range []int{1, 4} {
+		result, err := PermutationImportance(func() Predictor[float64] {
+			return nn.Copy()
+		}, data, Importance[float64]{
+			Repeats:    4,
+			Regression: true,
+			Workers:    workers,
+			Rand:       rand.New(rand.NewSource(1)),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		results[i] = result
+	}
+
+	result := results[0]
+	if len(result.Features) != 2 {
+		t.Fatalf("got %d features, want 2", len(result.Features))
+	}
+	relevant, irrelevant := result.Features[0], result.Features[1]
+	if relevant.Mean <= 10*irrelevant.Mean || relevant.Mean < result.Baseline {
+		t.Fatalf("importances %v and %v, baseline %v", relevant.Mean, irrelevant.Mean, result.Baseline)
+	}
+	if relevant.Variance <= 0 {
+		t.Fatalf("variance %v of %v", relevant.Variance, relevant.Scores)
+	}
+	for i, feature := range result.Features {
+		for j, score := range feature.Scores {
+			if score != results[1].Features[i].Scores[j] {
+				t.Fatalf("feature %d repeat %d: score %v with 1 worker and %v with 4", i, j, score, results[1].Features[i].Scores[j])
+			}
+		}
+	}
+
+	// r2 gets smaller when the relevant input is permuted, which is a degradation since it is maximized
+	result, err := PermutationImportance(func() Predictor[float64] {
+		return nn.Copy()
+	}, data, Importance[float64]{Repeats: 4, Metric: "r2", Regression: true, Rand: rand.New(rand.NewSource(1))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r2 := result.Features[0].Mean; r2 <= 0 {
+		t.Fatalf("r2 importance %v of the relevant input, baseline %v", r2, result.Baseline)
+	}
+
+	if _, err := PermutationImportance(func() Predictor[float64] {
+		return nn
+	}, data, Importance[float64]{Metric: "accuracy", Regression: true}); err == nil {
+		t.Fatal("expected an error for a classification metric of a regression")
+	}
+}
+
+func TestCopy(t *testing.T) {
+	nn, patterns := xorNetwork[float64](0, rand.New(rand.NewSource(1)))
+	c := nn.Copy()
+	outputs := append([]float64{}, nn.Update(patterns[1][0])...)
+	nn.Train(patterns, 10, 0.6, 0.4, false)
+	if got := c.Update(patterns[1][0]); got[0] != outputs[0] {
+		t.Fatalf("copy output changed from %v to %v", outputs[0], got[0])
+	}
+}
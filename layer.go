@@ -0,0 +1,54 @@
+package gobrain
+
+/*
+Layer is a layer applied to the inputs of a Network before its dense layers, like the convolution and pooling layers.
+
+The values of multichannel layers are stored channel major, the values of channel c of a layer of length n
+are at [c*n, (c+1)*n). Forward and Backward return buffers of the layer which are overwritten by the next call.
+*/
+type Layer[T Float] interface {
+	// Forward computes the outputs of the layer
+	Forward(inputs []T) []T
+	// Backward adds the gradients of the weights to 'gradient', unless it is nil, from the derivatives of the loss
+	// with respect to the outputs of the last Forward and returns the derivatives of the loss with respect to its inputs
+	Backward(deltas []T, gradient [][]T) []T
+	// Apply takes a gradient descent step with 'gradient' like ApplyGradient
+	Apply(gradient [][]T, lRate, mFactor T)
+	// NewGradient allocates a zero gradient for the weights, nil if the layer has no weights
+	NewGradient() [][]T
+	// Outputs is the number of outputs
+	Outputs() int
+	// Copy returns a deep copy of the layer
+	Copy() Layer[T]
+}
+
+// forward applies the layers to the inputs
+func (nn *Network[T]) forward(inputs []T) []T {
+	for _, layer := range nn.Layers {
+		inputs = layer.Forward(inputs)
+	}
+	return inputs
+}
+
+// backward back propagates the derivatives of the loss with respect to the outputs of the last layer through the layers,
+// the gradients of their weights are added to 'gradients', they are skipped for the layers it has no gradient for
+func (nn *Network[T]) backward(deltas []T, gradients [][][]T) []T {
+	for i := len(nn.Layers) - 1; i >= 0; i-- {
+		var gradient [][]T
+		if i < len(gradients) {
+			gradient = gradients[i]
+		}
+		deltas = nn.Layers[i].Backward(deltas, gradient)
+	}
+	return deltas
+}
+
+// PoolKind selects how a pooling layer combines its window
+type PoolKind int
+
+const (
+	// MaxPooling outputs the maximum of the window
+	MaxPooling PoolKind = iota
+	// AveragePooling outputs the mean of the window
+	AveragePooling
+)
@@ -0,0 +1,317 @@
+package gobrain
+
+import (
+	"math"
+	"sort"
+)
+
+/*
+Metrics holds the metrics computed by Evaluate.
+
+The classification metrics are set for classifiers and the regression metrics for regression,
+the mean squared error is set for both.
+*/
+type Metrics struct {
+	Regression bool
+
+	// Classification metrics, precision, recall and F1 are macro averages
+	Accuracy                             float64
+	Precision, Recall, F1                float64
+	MicroPrecision, MicroRecall, MicroF1 float64
+	LogLoss                              float64
+	// Areas under the ROC and precision recall curves, one versus rest macro averages over the classes
+	// they are defined for, the ones with positive and negative samples for ROCAUC and with positive
+	// samples for PRAUC, the other classes are skipped and the average is NaN if none is left
+	ROCAUC, PRAUC float64
+	// Confusion matrix, rows are the target classes and columns the predicted ones
+	Confusion [][]int
+
+	// Regression metrics, MAPE is a fraction and ignores the targets which are zero
+	MSE, RMSE, MAE, R2, MAPE float64
+}
+
+// Map returns the metrics by name
+func (m Metrics) Map() map[string]float64 {
+	if m.Regression {
+		return map[string]float64{
+			"mse":  m.MSE,
+			"rmse": m.RMSE,
+			"mae":  m.MAE,
+			"r2":   m.R2,
+			"mape": m.MAPE,
+		}
+	}
+	return map[string]float64{
+		"mse":             m.MSE,
+		"accuracy":        m.Accuracy,
+		"precision":       m.Precision,
+		"recall":          m.Recall,
+		"f1":              m.F1,
+		"micro_precision": m.MicroPrecision,
+		"micro_recall":    m.MicroRecall,
+		"micro_f1":        m.MicroF1,
+		"log_loss":        m.LogLoss,
+		"roc_auc":         m.ROCAUC,
+		"pr_auc":          m.PRAUC,
+	}
+}
+
+// maximized returns whether a larger value of a metric returned by Map is better, like accuracy, instead of an error like mse
+func maximized(metric string) bool {
+	switch metric {
+	case "accuracy", "precision", "recall", "f1", "micro_precision", "micro_recall", "micro_f1", "roc_auc", "pr_auc", "r2":
+		return true
+	}
+	return false
+}
+
+/*
+Evaluate computes the metrics of the Network on a dataset,
+the classification or regression metrics are selected by the Regression flag.
+
+A classifier with a single output predicts class 1 when the output is at least 0.5,
+otherwise the predicted class is the output with the largest value.
+*/
+func (nn *Network[T]) Evaluate(data Dataset[T]) Metrics {
+	return evaluate[T](nn, data, nn.Regression)
+}
+
+// evaluate computes the metrics of a model, the contexts of a Network are reset at the start of every sequence
+func evaluate[T Float](model Predictor[T], data Dataset[T], regression bool) Metrics {
+	outputs, targets := make([][]T, data.Len()), make([][]T, data.Len())
+	sequenced, _ := data.(Sequenced)
+	resetter, _ := model.(interface{ ResetContexts() })
+	for i := range outputs {
+		if resetter != nil && sequenced != nil && sequenced.SequenceStart(i) {
+			resetter.ResetContexts()
+		}
+		sample := data.Sample(i)
+		outputs[i] = append([]T{}, model.Update(sample.Input)...)
+		targets[i] = sample.Target
+	}
+	return EvaluateOutputs(outputs, targets, regression)
+}
+
+// EvaluateOutputs computes the metrics of any model from its outputs and the targets
+func EvaluateOutputs[T Float](outputs, targets [][]T, regression bool) Metrics {
+	m := Metrics{Regression: regression}
+	if len(outputs) == 0 {
+		return m
+	}
+
+	var squares, absolutes, percentages float64
+	var n, nonzero int
+	for i, output := range outputs {
+		for j, target := range targets[i] {
+			d := float64(target - output[j])
+			squares += d * d
+			absolutes += math.Abs(d)
+			if target != 0 {
+				percentages += math.Abs(d / float64(target))
+				nonzero++
+			}
+			n++
+		}
+	}
+	m.MSE = squares / float64(n)
+	if regression {
+		m.RMSE = math.Sqrt(m.MSE)
+		m.MAE = absolutes / float64(n)
+		if nonzero > 0 {
+			m.MAPE = percentages / float64(nonzero)
+		}
+		m.R2 = r2(outputs, targets)
+		return m
+	}
+
+	classes := len(targets[0])
+	if classes == 1 {
+		classes = 2
+	}
+	m.Confusion = make([][]int, classes)
+	for i := range m.Confusion {
+		m.Confusion[i] = make([]int, classes)
+	}
+	for i, output := range outputs {
+		m.Confusion[class(targets[i])][class(output)]++
+	}
+
+	var correct int
+	for c := 0; c < classes; c++ {
+		var predicted, actual int
+		for k := 0; k < classes; k++ {
+			predicted += m.Confusion[k][c]
+			actual += m.Confusion[c][k]
+		}
+		tp := m.Confusion[c][c]
+		correct += tp
+		var precision, recall float64
+		if predicted > 0 {
+			precision = float64(tp) / float64(predicted)
+		}
+		if actual > 0 {
+			recall = float64(tp) / float64(actual)
+		}
+		m.Precision += precision / float64(classes)
+		m.Recall += recall / float64(classes)
+		m.F1 += f1(precision, recall) / float64(classes)
+	}
+	m.Accuracy = float64(correct) / float64(len(outputs))
+	// every sample has a single label so the micro averages are the accuracy
+	m.MicroPrecision, m.MicroRecall = m.Accuracy, m.Accuracy
+	m.MicroF1 = f1(m.MicroPrecision, m.MicroRecall)
+
+	const epsilon = 1e-15
+	for i, output := range outputs {
+		var p float64
+		if len(output) == 1 {
+			p = float64(output[0])
+			if class(targets[i]) == 0 {
+				p = 1 - p
+			}
+		} else {
+			var sum float64
+			for _, v := range output {
+				sum += float64(v)
+			}
+			if sum > 0 {
+				p = float64(output[class(targets[i])]) / sum
+			}
+		}
+		m.LogLoss -= math.Log(math.Min(math.Max(p, epsilon), 1-epsilon))
+	}
+	m.LogLoss /= float64(len(outputs))
+
+	// one versus rest for more than two classes
+	scored := make([]scoredLabel, len(outputs))
+	positives := []int{1}
+	if len(targets[0]) > 1 {
+		positives = positives[:0]
+		for c := 0; c < classes; c++ {
+			positives = append(positives, c)
+		}
+	}
+	var rocs, prs int
+	for _, c := range positives {
+		for i, output := range outputs {
+			score := output[0]
+			if len(output) > 1 {
+				score = output[c]
+			}
+			scored[i] = scoredLabel{score: float64(score), positive: class(targets[i]) == c}
+		}
+		if auc := rocAUC(scored); !math.IsNaN(auc) {
+			m.ROCAUC += auc
+			rocs++
+		}
+		if ap := averagePrecision(scored); !math.IsNaN(ap) {
+			m.PRAUC += ap
+			prs++
+		}
+	}
+	m.ROCAUC /= float64(rocs)
+	m.PRAUC /= float64(prs)
+
+	return m
+}
+
+func f1(precision, recall float64) float64 {
+	if precision+recall == 0 {
+		return 0
+	}
+	return 2 * precision * recall / (precision + recall)
+}
+
+// r2 is the coefficient of determination averaged over the outputs
+func r2[T Float](outputs, targets [][]T) float64 {
+	var sum float64
+	for j := range targets[0] {
+		var mean, residual, total float64
+		for _, target := range targets {
+			mean += float64(target[j])
+		}
+		mean /= float64(len(targets))
+		for i, target := range targets {
+			d := float64(target[j] - outputs[i][j])
+			residual += d * d
+			total += (float64(target[j]) - mean) * (float64(target[j]) - mean)
+		}
+		if total == 0 {
+			if residual == 0 {
+				sum++
+			}
+			continue
+		}
+		sum += 1 - residual/total
+	}
+	return sum / float64(len(targets[0]))
+}
+
+type scoredLabel struct {
+	score    float64
+	positive bool
+}
+
+// rocAUC computes the area under the ROC curve with the Mann-Whitney U statistic, ties count for half,
+// it is NaN without positives or negatives
+func rocAUC(scored []scoredLabel) float64 {
+	sorted := append([]scoredLabel{}, scored...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].score < sorted[j].score
+	})
+	var ranks float64
+	var positives int
+	for i := 0; i < len(sorted); {
+		j := i
+		for j < len(sorted) && sorted[j].score == sorted[i].score {
+			j++
+		}
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if sorted[k].positive {
+				ranks += rank
+				positives++
+			}
+		}
+		i = j
+	}
+	negatives := len(sorted) - positives
+	if positives == 0 || negatives == 0 {
+		return math.NaN()
+	}
+	return (ranks - float64(positives*(positives+1))/2) / float64(positives*negatives)
+}
+
+// averagePrecision computes the area under the precision recall curve as the average precision, it is NaN without positives
+func averagePrecision(scored []scoredLabel) float64 {
+	sorted := append([]scoredLabel{}, scored...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].score > sorted[j].score
+	})
+	var positives int
+	for _, s := range sorted {
+		if s.positive {
+			positives++
+		}
+	}
+	if positives == 0 {
+		return math.NaN()
+	}
+	var area float64
+	var tp, fp int
+	for i := 0; i < len(sorted); {
+		j, found := i, 0
+		for j < len(sorted) && sorted[j].score == sorted[i].score {
+			if sorted[j].positive {
+				found++
+				tp++
+			} else {
+				fp++
+			}
+			j++
+		}
+		area += float64(found) / float64(positives) * float64(tp) / float64(tp+fp)
+		i = j
+	}
+	return area
+}
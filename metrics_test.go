@@ -0,0 +1,87 @@
+package gobrain
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestEvaluateBinary(t *testing.T) {
+	outputs := [][]float64{{.9}, {.8}, {.3}, {.6}, {.2}, {.1}}
+	targets := [][]float64{{1}, {1}, {1}, {0}, {0}, {0}}
+	m := EvaluateOutputs(outputs, targets, false)
+
+	if !reflect.DeepEqual(m.Confusion, [][]int{{2, 1}, {1, 2}}) {
+		t.Fatalf("confusion matrix %v", m.Confusion)
+	}
+	expected := map[string]float64{
+		"accuracy":        4. / 6,
+		"precision":       2. / 3,
+		"recall":          2. / 3,
+		"f1":              2. / 3,
+		"micro_precision": 4. / 6,
+		"micro_recall":    4. / 6,
+		"micro_f1":        4. / 6,
+		"roc_auc":         8. / 9,
+		"pr_auc":          (1 + 1 + 3./4) / 3,
+		"log_loss":        -(math.Log(.9) + math.Log(.8) + math.Log(.3) + math.Log(.4) + math.Log(.8) + math.Log(.9)) / 6,
+	}
+	for name, value := range m.Map() {
+		if e, ok := expected[name]; ok && math.Abs(e-value) > 1e-12 {
+			t.Fatalf("%s is %v, want %v", name, value, e)
+		}
+	}
+}
+
+func TestEvaluateMultiClass(t *testing.T) {
+	outputs := [][]float32{{.8, .1, .1}, {.2, .7, .1}, {.1, .2, .7}, {.6, .3, .1}}
+	targets := [][]float32{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}, {0, 1, 0}}
+	m := EvaluateOutputs(outputs, targets, false)
+	if m.Accuracy != .75 || m.ROCAUC != 1 {
+		t.Fatalf("accuracy %v, ROC AUC %v", m.Accuracy, m.ROCAUC)
+	}
+	if !reflect.DeepEqual(m.Confusion, [][]int{{1, 0, 0}, {1, 1, 0}, {0, 0, 1}}) {
+		t.Fatalf("confusion matrix %v", m.Confusion)
+	}
+	if math.Abs(m.Precision-(.5+1+1)/3) > 1e-9 || math.Abs(m.Recall-(1+.5+1)/3) > 1e-9 {
+		t.Fatalf("precision %v, recall %v", m.Precision, m.Recall)
+	}
+}
+
+func TestEvaluateMissingClass(t *testing.T) {
+	// class 2 has no samples, it is left out of the averages of the areas
+	outputs := [][]float64{{.8, .1, .1}, {.2, .7, .1}, {.6, .3, .1}, {.3, .6, .1}}
+	targets := [][]float64{{1, 0, 0}, {0, 1, 0}, {1, 0, 0}, {0, 1, 0}}
+	if m := EvaluateOutputs(outputs, targets, false); m.ROCAUC != 1 || m.PRAUC != 1 {
+		t.Fatalf("ROC AUC %v, PR AUC %v", m.ROCAUC, m.PRAUC)
+	}
+
+	m := EvaluateOutputs([][]float64{{.9}, {.4}}, [][]float64{{1}, {1}}, false)
+	if !math.IsNaN(m.ROCAUC) || m.PRAUC != 1 {
+		t.Fatalf("ROC AUC %v, PR AUC %v without negatives", m.ROCAUC, m.PRAUC)
+	}
+}
+
+func TestEvaluateRegression(t *testing.T) {
+	outputs := [][]float64{{1}, {2}, {4}}
+	targets := [][]float64{{1}, {3}, {5}}
+	m := EvaluateOutputs(outputs, targets, true)
+	if m.MSE != 2./3 || m.MAE != 2./3 || m.RMSE != math.Sqrt(2./3) {
+		t.Fatalf("mse %v, mae %v, rmse %v", m.MSE, m.MAE, m.RMSE)
+	}
+	if math.Abs(m.R2-(1-2./8)) > 1e-12 || math.Abs(m.MAPE-(1./3+1./5)/3) > 1e-12 {
+		t.Fatalf("r2 %v, mape %v", m.R2, m.MAPE)
+	}
+	if _, ok := m.Map()["accuracy"]; ok {
+		t.Fatal("regression metrics include accuracy")
+	}
+}
+
+func TestNetworkEvaluate(t *testing.T) {
+	nn, patterns := xorNetwork[float64](0, rand.New(rand.NewSource(1)))
+	nn.Train(patterns, 2000, 0.6, 0.4, false)
+	if m := nn.Evaluate(Patterns[float64](patterns)); m.Accuracy != 1 || m.ROCAUC != 1 {
+		t.Fatalf("accuracy %v, ROC AUC %v", m.Accuracy, m.ROCAUC)
+	}
+}
@@ -0,0 +1,206 @@
+package gobrain
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+func init() {
+	gob.Register(&MinMax[float32]{})
+	gob.Register(&MinMax[float64]{})
+	gob.Register(&ZScore[float32]{})
+	gob.Register(&ZScore[float64]{})
+	gob.Register(&Robust[float32]{})
+	gob.Register(&Robust[float64]{})
+	gob.Register(&OneHot[float32]{})
+	gob.Register(&OneHot[float64]{})
+	gob.Register(&LabelEncoder[float32]{})
+	gob.Register(&LabelEncoder[float64]{})
+	gob.Register(&Pipeline[float32]{})
+	gob.Register(&Pipeline[float64]{})
+	gob.Register(&Conv1D[float32]{})
+	gob.Register(&Conv1D[float64]{})
+	gob.Register(&Pool1D[float32]{})
+	gob.Register(&Pool1D[float64]{})
+	gob.Register(&Conv2D[float32]{})
+	gob.Register(&Conv2D[float64]{})
+	gob.Register(&Pool2D[float32]{})
+	gob.Register(&Pool2D[float64]{})
+}
+
+/*
+Model is a network with a preprocessing pipeline for its inputs and one for its targets.
+
+The inputs are transformed before they are given to the network and the outputs
+of the network are inverted with the target pipeline, so the model works with the
+original units, for example for regression.
+*/
+type Model[T Float] struct {
+	Network *Network[T]
+	// Either pipeline can be nil
+	Input, Output *Pipeline[T]
+}
+
+// Fit fits the pipelines on the inputs and targets of a dataset
+func (m *Model[T]) Fit(data Dataset[T]) error {
+	inputs, targets := make([][]T, data.Len()), make([][]T, data.Len())
+	for i := range inputs {
+		sample := data.Sample(i)
+		inputs[i], targets[i] = sample.Input, sample.Target
+	}
+	if m.Input != nil {
+		if err := m.Input.Fit(inputs); err != nil {
+			return err
+		}
+	}
+	if m.Output != nil {
+		if err := m.Output.Fit(targets); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Transform applies the pipelines to a dataset
+func (m *Model[T]) Transform(data Dataset[T]) Samples[T] {
+	samples := make(Samples[T], data.Len())
+	for i := range samples {
+		sample := data.Sample(i)
+		if m.Input != nil {
+			sample.Input = m.Input.Transform(sample.Input)
+		}
+		if m.Output != nil {
+			sample.Target = m.Output.Transform(sample.Target)
+		}
+		samples[i] = sample
+	}
+	return samples
+}
+
+// Train fits the pipelines and trains the network on the transformed dataset
+func (m *Model[T]) Train(data Dataset[T], config Config[T]) ([]T, error) {
+	if err := m.Fit(data); err != nil {
+		return nil, err
+	}
+	return m.Network.TrainDataset(m.Transform(data), config), nil
+}
+
+// Update activates the network with transformed inputs and returns the inverted outputs
+func (m *Model[T]) Update(inputs []T) []T {
+	if m.Input != nil {
+		inputs = m.Input.Transform(inputs)
+	}
+	outputs := m.Network.Update(inputs)
+	if m.Output != nil {
+		return m.Output.Inverse(outputs)
+	}
+	return outputs
+}
+
+// activated is implemented by the layers with activation functions, they are encoded by name
+type activated[T Float] interface {
+	activationFuncs() []*func(x T) T
+}
+
+// savedFuncs returns the activation functions of the network and of its Layers
+func (nn *Network[T]) savedFuncs() []*func(x T) T {
+	funcs := nn.activationFuncs()
+	for _, layer := range nn.Layers {
+		if a, ok := layer.(activated[T]); ok {
+			funcs = append(funcs, a.activationFuncs()...)
+		}
+	}
+	return funcs
+}
+
+// activationName returns the name of a built in activation function, the empty string if it is nil
+func activationName[T Float](activation func(x T) T) (string, error) {
+	if activation == nil {
+		return "", nil
+	}
+	pointer := reflect.ValueOf(activation).Pointer()
+	for name, builtin := range activations[T]() {
+		if reflect.ValueOf(builtin).Pointer() == pointer {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("gobrain: custom activation functions can't be saved, use NamedActivation")
+}
+
+// plainNetwork has the fields of Network without its gob methods
+type plainNetwork[T Float] Network[T]
+
+// gobNetwork is the encoding of a Network, gob can't encode functions so the activations are stored by name
+type gobNetwork[T Float] struct {
+	Network     *plainNetwork[T]
+	Activations []string
+}
+
+/*
+GobEncode encodes the network with encoding/gob.
+
+The activation functions of the network and of its Layers are stored by name, so they
+have to be nil or built in ones set by Init, the Set*Activation methods or NamedActivation,
+an error is returned for a custom activation function.
+*/
+func (nn *Network[T]) GobEncode() ([]byte, error) {
+	funcs := nn.savedFuncs()
+	names := make([]string, len(funcs))
+	for i, f := range funcs {
+		name, err := activationName(*f)
+		if err != nil {
+			return nil, err
+		}
+		names[i] = name
+	}
+	buffer := &bytes.Buffer{}
+	if err := gob.NewEncoder(buffer).Encode(gobNetwork[T]{Network: (*plainNetwork[T])(nn), Activations: names}); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// GobDecode decodes a network written by GobEncode, with its activation functions, and migrates it to the current Version
+func (nn *Network[T]) GobDecode(data []byte) error {
+	saved := gobNetwork[T]{Network: (*plainNetwork[T])(nn)}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&saved); err != nil {
+		return err
+	}
+	funcs := nn.savedFuncs()
+	if len(funcs) != len(saved.Activations) {
+		return fmt.Errorf("gobrain: %d activation functions were saved for %d", len(saved.Activations), len(funcs))
+	}
+	for i, name := range saved.Activations {
+		if name == "" {
+			continue
+		}
+		activation := builtin[T](name)
+		if activation == nil {
+			return fmt.Errorf("gobrain: unknown activation %q", name)
+		}
+		*funcs[i] = activation
+	}
+	nn.migrate()
+	return nil
+}
+
+/*
+Save writes the network and the fitted pipelines with encoding/gob.
+
+The activation functions are saved by name by Network.GobEncode, an error is returned for a custom one.
+*/
+func (m *Model[T]) Save(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(m)
+}
+
+// LoadModel reads a model written by Save
+func LoadModel[T Float](r io.Reader) (*Model[T], error) {
+	m := &Model[T]{}
+	if err := gob.NewDecoder(r).Decode(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
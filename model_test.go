@@ -0,0 +1,96 @@
+package gobrain
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestModel(t *testing.T) {
+	var data Samples[float64]
+	for i := 0; i < 20; i++ {
+		x := float64(i)
+		data = append(data, Sample[float64]{Input: []float64{x}, Target: []float64{100*x*x + 50}})
+	}
+
+	nn := &FeedForward{}
+	nn.SetRand(rand.New(rand.NewSource(0)))
+	nn.Init(1, 5, 1)
+	model := &Model[float64]{
+		Network: nn,
+		Input:   NewPipeline[float64](&ZScore[float64]{}),
+		Output:  NewPipeline[float64](&MinMax[float64]{Low: .1, High: .9}),
+	}
+	if _, err := model.Train(data, func(context *Context[float64]) *Context[float64] {
+		context.Iterations = 5000
+		context.LRate, context.MFactor = .3, .1
+		return context
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for _, sample := range data {
+		if y := model.Update(sample.Input); math.Abs(y[0]-sample.Target[0]) > 2000 {
+			t.Fatalf("%v -> %v, want %v", sample.Input, y, sample.Target)
+		}
+	}
+
+	buffer := &bytes.Buffer{}
+	if err := model.Save(buffer); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadModel[float64](buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sample := range data {
+		if a, b := model.Update(sample.Input), loaded.Update(sample.Input); a[0] != b[0] {
+			t.Fatalf("%v -> %v, loaded model %v", sample.Input, a, b)
+		}
+	}
+}
+
+func TestModelActivations(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	tanh, dtanh, err := NamedActivation[float64]("tanh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := &Conv1D[float64]{Channels: 1, Length: 8, Filters: 2, Kernel: 3, Activation: tanh, DActivation: dtanh}
+	first.Init(rnd)
+	second := &Conv1D[float64]{Channels: 2, Length: first.Outputs() / 2, Filters: 2, Kernel: 2}
+	second.Init(rnd)
+	nn := &FeedForward{Layers: []Layer[float64]{first, second}}
+	nn.SetRand(rnd)
+	nn.Init(second.Outputs(), 4, 2)
+	nn.SetTanhActivation()
+	nn.SetReLUHiddenActivation()
+	model := &Model[float64]{Network: nn}
+
+	buffer := &bytes.Buffer{}
+	if err := model.Save(buffer); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadModel[float64](buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		inputs := make([]float64, 8)
+		for j := range inputs {
+			inputs[j] = rnd.Float64()*2 - 1
+		}
+		a := append([]float64{}, model.Update(inputs)...)
+		if b := loaded.Update(inputs); a[0] != b[0] || a[1] != b[1] {
+			t.Fatalf("%v -> %v, loaded model %v", inputs, a, b)
+		}
+	}
+
+	nn.OutputActivation = func(x float64) float64 { return x }
+	if err := model.Save(&bytes.Buffer{}); err == nil {
+		t.Fatal("a custom activation function was saved")
+	}
+	if _, _, err := NamedActivation[float64]("softmax"); err == nil {
+		t.Fatal("got an unknown activation")
+	}
+}
@@ -0,0 +1,100 @@
+package gobrain
+
+import (
+	"log"
+	"math/rand"
+)
+
+// NoiseKind selects the distribution of the noise injected when training
+type NoiseKind int
+
+const (
+	// GaussianNoise has zero mean and the level as standard deviation
+	GaussianNoise NoiseKind = iota
+	// UniformNoise is uniform in [-level, level)
+	UniformNoise
+)
+
+/*
+Noise configures the noise injected into the layers of a Network with UpdateWithNoise when training,
+a new sample of noise is drawn from the random source of the Network for every step.
+*/
+type Noise[T Float] struct {
+	Kind NoiseKind
+	// Noise levels of the input, hidden and output layers, a zero level disables the noise of a layer
+	Input, Hidden, Output T
+	// Whether the noisy values are clamped to [0, 1] like UpdateWithNoise does, which suits sigmoid activations
+	// but clips tanh and ReLU hidden activations and inputs outside of [0, 1]
+	Clamp bool
+}
+
+func (n *Noise[T]) fill(rnd *rand.Rand, noise [][]T) {
+	for l, level := range [3]T{n.Input, n.Hidden, n.Output} {
+		layer := noise[l]
+		if level == 0 {
+			for i := range layer {
+				layer[i] = 0
+			}
+			continue
+		}
+		for i := range layer {
+			if n.Kind == UniformNoise {
+				layer[i] = random[T](rnd, -level, level)
+			} else {
+				layer[i] = normal[T](rnd) * level
+			}
+		}
+	}
+}
+
+// SampleNoise draws a sample of noise for UpdateWithNoise from the random source of the Network
+func (nn *Network[T]) SampleNoise(noise Noise[T]) [][]T {
+	sample := [][]T{make([]T, nn.NInputs-1), make([]T, nn.NHiddens-1), make([]T, nn.NOutputs)}
+	noise.fill(nn.rnd, sample)
+	return sample
+}
+
+// updateWithNoise activates the Network with noise, the noisy values are clamped like UpdateWithNoise if clamp is set
+func (nn *Network[T]) updateWithNoise(inputs []T, noise [][]T, hidden, output Activation[T], clamp bool) []T {
+	inputs = nn.forward(inputs)
+	if len(inputs) != nn.NInputs-1 {
+		log.Fatal("Error: wrong number of inputs")
+	}
+
+	clampInputsOutputs := clamp && !nn.Regression
+	for i := 0; i < nn.NInputs-1; i++ {
+		nn.InputActivations[i] = inputs[i] + noise[0][i]
+		if clampInputsOutputs {
+			nn.InputActivations[i] = normalize(nn.InputActivations[i])
+		}
+	}
+
+	for i := 0; i < nn.NHiddens-1; i++ {
+		sum := dot(nn.InputActivations, nn.InputWeights[i])
+
+		// compute contexts sum
+		for k := 0; k < len(nn.Contexts); k++ {
+			for j := 0; j < nn.NHiddens-1; j++ {
+				sum += nn.Contexts[k][j]
+			}
+		}
+
+		nn.HiddenActivations[i] = hidden(sum) + noise[1][i]
+		if clamp {
+			nn.HiddenActivations[i] = normalize(nn.HiddenActivations[i])
+		}
+	}
+
+	nn.pushContexts()
+
+	for i := 0; i < nn.NOutputs; i++ {
+		sum := dot(nn.HiddenActivations, nn.OutputWeights[i])
+
+		nn.OutputActivations[i] = output(sum) + noise[2][i]
+		if clampInputsOutputs {
+			nn.OutputActivations[i] = normalize(nn.OutputActivations[i])
+		}
+	}
+
+	return nn.OutputActivations
+}
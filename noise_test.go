@@ -0,0 +1,78 @@
+package gobrain
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestSampleNoise(t *testing.T) {
+	nn := &Network[float64]{}
+	nn.SetRand(rand.New(rand.NewSource(1)))
+	nn.Init(1000, 1000, 1000)
+
+	uniform := nn.SampleNoise(Noise[float64]{Kind: UniformNoise, Input: .5, Output: .1})
+	for _, v := range uniform[0] {
+		if v < -.5 || v >= .5 {
+			t.Fatalf("uniform noise %v outside [-0.5, 0.5)", v)
+		}
+	}
+	for _, v := range uniform[1] {
+		if v != 0 {
+			t.Fatalf("got hidden noise %v with a zero level", v)
+		}
+	}
+
+	gaussian := nn.SampleNoise(Noise[float64]{Hidden: 2})
+	var sum, squares float64
+	for _, v := range gaussian[1] {
+		sum += v
+		squares += v * v
+	}
+	mean := sum / float64(len(gaussian[1]))
+	if std := math.Sqrt(squares/float64(len(gaussian[1])) - mean*mean); math.Abs(mean) > .2 || math.Abs(std-2) > .2 {
+		t.Fatalf("gaussian noise with mean %v and deviation %v, want 0 and 2", mean, std)
+	}
+}
+
+func TestUpdateWithNoise(t *testing.T) {
+	nn, patterns := xorNetwork[float64](0, rand.New(rand.NewSource(1)))
+	zero := nn.SampleNoise(Noise[float64]{})
+	for _, p := range patterns {
+		expected := append([]float64{}, nn.Update(p[0])...)
+		if got := nn.UpdateWithNoise(p[0], zero); got[0] != expected[0] {
+			t.Fatalf("got %v without noise, want %v", got[0], expected[0])
+		}
+	}
+	large := [][]float64{{5, -5}, {5, -5, 5, -5}, {5}}
+	if got := nn.UpdateWithNoise(patterns[0][0], large); got[0] != 1 {
+		t.Fatalf("got %v, want the output clamped to 1", got[0])
+	}
+}
+
+func TestNoiseTraining(t *testing.T) {
+	for _, kind := range []NoiseKind{GaussianNoise, UniformNoise} {
+		nn, patterns := xorNetwork[float64](0, rand.New(rand.NewSource(2)))
+		noisy := func(iterations int) Config[float64] {
+			return func(context *Context[float64]) *Context[float64] {
+				context.Iterations = iterations
+				context.Noise = &Noise[float64]{Kind: kind, Input: .05, Hidden: .05}
+				return context
+			}
+		}
+		nn.TrainWithConfig(patterns, noisy(1000))
+		if accuracy := nn.Evaluate(Patterns[float64](patterns)).Accuracy; accuracy != 1 {
+			t.Fatalf("noise %d: accuracy %v after training", kind, accuracy)
+		}
+
+		once := testing.AllocsPerRun(10, func() {
+			nn.TrainWithConfig(patterns, noisy(1))
+		})
+		many := testing.AllocsPerRun(10, func() {
+			nn.TrainWithConfig(patterns, noisy(100))
+		})
+		if once != many {
+			t.Fatalf("noise allocates per epoch: %v allocations for 1 epoch, %v for 100", once, many)
+		}
+	}
+}
@@ -0,0 +1,366 @@
+package gobrain
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Transformer is a preprocessing step which is fitted on data and can be inverted
+type Transformer[T Float] interface {
+	// Fit learns the parameters of the transformation from the rows of data
+	Fit(data [][]T) error
+	// Transform returns the transformed copy of a row
+	Transform(x []T) []T
+	// Inverse returns the row the transformed row 'y' was computed from
+	Inverse(y []T) []T
+}
+
+func transpose[T Float](data [][]T) ([][]T, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("gobrain: no data to fit")
+	}
+	columns := matrix[T](len(data[0]), len(data))
+	for i, row := range data {
+		if len(row) != len(columns) {
+			return nil, fmt.Errorf("gobrain: row %d has %d columns, want %d", i, len(row), len(columns))
+		}
+		for j, v := range row {
+			columns[j][i] = v
+		}
+	}
+	return columns, nil
+}
+
+// MinMax scales every column to the range [Low, High], [0, 1] if both are zero
+type MinMax[T Float] struct {
+	Low, High T
+	Min, Max  []T
+}
+
+func (m *MinMax[T]) Fit(data [][]T) error {
+	columns, err := transpose(data)
+	if err != nil {
+		return err
+	}
+	if m.Low == 0 && m.High == 0 {
+		m.High = 1
+	}
+	m.Min, m.Max = make([]T, len(columns)), make([]T, len(columns))
+	for i, column := range columns {
+		m.Min[i], m.Max[i] = column[0], column[0]
+		for _, v := range column {
+			if v < m.Min[i] {
+				m.Min[i] = v
+			}
+			if v > m.Max[i] {
+				m.Max[i] = v
+			}
+		}
+	}
+	return nil
+}
+
+func (m *MinMax[T]) scale(i int) T {
+	if d := m.Max[i] - m.Min[i]; d != 0 {
+		return (m.High - m.Low) / d
+	}
+	return 1
+}
+
+func (m *MinMax[T]) Transform(x []T) []T {
+	y := make([]T, len(x))
+	for i, v := range x {
+		y[i] = (v-m.Min[i])*m.scale(i) + m.Low
+	}
+	return y
+}
+
+func (m *MinMax[T]) Inverse(y []T) []T {
+	x := make([]T, len(y))
+	for i, v := range y {
+		x[i] = (v-m.Low)/m.scale(i) + m.Min[i]
+	}
+	return x
+}
+
+// ZScore standardizes every column to zero mean and unit variance
+type ZScore[T Float] struct {
+	Mean, Std []T
+}
+
+func (z *ZScore[T]) Fit(data [][]T) error {
+	columns, err := transpose(data)
+	if err != nil {
+		return err
+	}
+	z.Mean, z.Std = make([]T, len(columns)), make([]T, len(columns))
+	for i, column := range columns {
+		var sum, squares float64
+		for _, v := range column {
+			sum += float64(v)
+		}
+		mean := sum / float64(len(column))
+		for _, v := range column {
+			squares += (float64(v) - mean) * (float64(v) - mean)
+		}
+		z.Mean[i], z.Std[i] = T(mean), T(math.Sqrt(squares/float64(len(column))))
+		if z.Std[i] == 0 {
+			z.Std[i] = 1
+		}
+	}
+	return nil
+}
+
+func (z *ZScore[T]) Transform(x []T) []T {
+	y := make([]T, len(x))
+	for i, v := range x {
+		y[i] = (v - z.Mean[i]) / z.Std[i]
+	}
+	return y
+}
+
+func (z *ZScore[T]) Inverse(y []T) []T {
+	x := make([]T, len(y))
+	for i, v := range y {
+		x[i] = v*z.Std[i] + z.Mean[i]
+	}
+	return x
+}
+
+// Robust centers every column on its median and scales it by its interquartile range
+type Robust[T Float] struct {
+	Median, IQR []T
+}
+
+func quantile[T Float](sorted []T, q float64) T {
+	position := q * float64(len(sorted)-1)
+	i := int(position)
+	if i+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	f := T(position - float64(i))
+	return sorted[i] + f*(sorted[i+1]-sorted[i])
+}
+
+func (r *Robust[T]) Fit(data [][]T) error {
+	columns, err := transpose(data)
+	if err != nil {
+		return err
+	}
+	r.Median, r.IQR = make([]T, len(columns)), make([]T, len(columns))
+	for i, column := range columns {
+		sort.Slice(column, func(a, b int) bool {
+			return column[a] < column[b]
+		})
+		r.Median[i] = quantile(column, .5)
+		r.IQR[i] = quantile(column, .75) - quantile(column, .25)
+		if r.IQR[i] == 0 {
+			r.IQR[i] = 1
+		}
+	}
+	return nil
+}
+
+func (r *Robust[T]) Transform(x []T) []T {
+	y := make([]T, len(x))
+	for i, v := range x {
+		y[i] = (v - r.Median[i]) / r.IQR[i]
+	}
+	return y
+}
+
+func (r *Robust[T]) Inverse(y []T) []T {
+	x := make([]T, len(y))
+	for i, v := range y {
+		x[i] = v*r.IQR[i] + r.Median[i]
+	}
+	return x
+}
+
+func categories[T Float](column []T) []T {
+	var values []T
+	seen := make(map[T]bool)
+	for _, v := range column {
+		if !seen[v] {
+			seen[v] = true
+			values = append(values, v)
+		}
+	}
+	sort.Slice(values, func(a, b int) bool {
+		return values[a] < values[b]
+	})
+	return values
+}
+
+func indexOf(columns []int, i int) int {
+	for j, column := range columns {
+		if column == i {
+			return j
+		}
+	}
+	return -1
+}
+
+/*
+OneHot replaces the categorical columns listed in Columns, or all of them if not set,
+with one column per category. Unknown categories are encoded with all zeros.
+*/
+type OneHot[T Float] struct {
+	Columns    []int
+	Width      int
+	Categories [][]T
+}
+
+func (o *OneHot[T]) Fit(data [][]T) error {
+	columns, err := transpose(data)
+	if err != nil {
+		return err
+	}
+	if o.Columns == nil {
+		for i := range columns {
+			o.Columns = append(o.Columns, i)
+		}
+	}
+	o.Width, o.Categories = len(columns), make([][]T, len(o.Columns))
+	for j, i := range o.Columns {
+		o.Categories[j] = categories(columns[i])
+	}
+	return nil
+}
+
+func (o *OneHot[T]) Transform(x []T) []T {
+	var y []T
+	for i, v := range x {
+		j := indexOf(o.Columns, i)
+		if j == -1 {
+			y = append(y, v)
+			continue
+		}
+		for _, category := range o.Categories[j] {
+			if v == category {
+				y = append(y, 1)
+			} else {
+				y = append(y, 0)
+			}
+		}
+	}
+	return y
+}
+
+// Inverse decodes every one-hot block to the category with the largest value
+func (o *OneHot[T]) Inverse(y []T) []T {
+	x := make([]T, 0, o.Width)
+	for i := 0; i < o.Width; i++ {
+		j := indexOf(o.Columns, i)
+		if j == -1 {
+			x, y = append(x, y[0]), y[1:]
+			continue
+		}
+		n := len(o.Categories[j])
+		max := 0
+		for k := range y[:n] {
+			if y[k] > y[max] {
+				max = k
+			}
+		}
+		x, y = append(x, o.Categories[j][max]), y[n:]
+	}
+	return x
+}
+
+/*
+LabelEncoder maps the values of the columns listed in Columns, or all of them if not set,
+to the index of the value in the sorted list of labels. Unknown labels are encoded as -1.
+*/
+type LabelEncoder[T Float] struct {
+	Columns []int
+	Labels  [][]T
+}
+
+func (l *LabelEncoder[T]) Fit(data [][]T) error {
+	columns, err := transpose(data)
+	if err != nil {
+		return err
+	}
+	if l.Columns == nil {
+		for i := range columns {
+			l.Columns = append(l.Columns, i)
+		}
+	}
+	l.Labels = make([][]T, len(l.Columns))
+	for j, i := range l.Columns {
+		l.Labels[j] = categories(columns[i])
+	}
+	return nil
+}
+
+func (l *LabelEncoder[T]) Transform(x []T) []T {
+	y := append([]T{}, x...)
+	for j, i := range l.Columns {
+		labels := l.Labels[j]
+		k := sort.Search(len(labels), func(k int) bool {
+			return labels[k] >= x[i]
+		})
+		if k < len(labels) && labels[k] == x[i] {
+			y[i] = T(k)
+		} else {
+			y[i] = -1
+		}
+	}
+	return y
+}
+
+// Inverse maps the encoded values, rounded to the nearest label, back to the labels
+func (l *LabelEncoder[T]) Inverse(y []T) []T {
+	x := append([]T{}, y...)
+	for j, i := range l.Columns {
+		labels := l.Labels[j]
+		k := int(math.Round(float64(y[i])))
+		if k < 0 {
+			k = 0
+		} else if k >= len(labels) {
+			k = len(labels) - 1
+		}
+		x[i] = labels[k]
+	}
+	return x
+}
+
+// Pipeline chains transformers, they are fitted and applied in order and inverted in reverse order
+type Pipeline[T Float] struct {
+	Steps []Transformer[T]
+}
+
+// NewPipeline creates a pipeline from transformers
+func NewPipeline[T Float](steps ...Transformer[T]) *Pipeline[T] {
+	return &Pipeline[T]{Steps: steps}
+}
+
+func (p *Pipeline[T]) Fit(data [][]T) error {
+	for _, step := range p.Steps {
+		if err := step.Fit(data); err != nil {
+			return err
+		}
+		transformed := make([][]T, len(data))
+		for i, row := range data {
+			transformed[i] = step.Transform(row)
+		}
+		data = transformed
+	}
+	return nil
+}
+
+func (p *Pipeline[T]) Transform(x []T) []T {
+	for _, step := range p.Steps {
+		x = step.Transform(x)
+	}
+	return x
+}
+
+func (p *Pipeline[T]) Inverse(y []T) []T {
+	for i := len(p.Steps) - 1; i >= 0; i-- {
+		y = p.Steps[i].Inverse(y)
+	}
+	return y
+}
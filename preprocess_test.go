@@ -0,0 +1,54 @@
+package gobrain
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestTransformers(t *testing.T) {
+	data := [][]float64{
+		{1, 10, 3},
+		{2, 20, 1},
+		{3, 30, 3},
+		{4, 70, 2},
+	}
+	transformers := []Transformer[float64]{
+		&MinMax[float64]{},
+		&MinMax[float64]{Low: -1, High: 1},
+		&ZScore[float64]{},
+		&Robust[float64]{},
+		&LabelEncoder[float64]{},
+		&OneHot[float64]{Columns: []int{2}},
+		NewPipeline[float64](&OneHot[float64]{Columns: []int{2}}, &ZScore[float64]{}),
+	}
+	for _, transformer := range transformers {
+		if err := transformer.Fit(data); err != nil {
+			t.Fatal(err)
+		}
+		for _, row := range data {
+			x := transformer.Inverse(transformer.Transform(row))
+			for i := range row {
+				if math.Abs(x[i]-row[i]) > 1e-9 {
+					t.Fatalf("%T: %v -> %v", transformer, row, x)
+				}
+			}
+		}
+	}
+
+	minmax := &MinMax[float64]{}
+	minmax.Fit(data)
+	if y := minmax.Transform(data[3]); !reflect.DeepEqual(y, []float64{1, 1, .5}) {
+		t.Fatalf("MinMax: %v", y)
+	}
+	onehot := &OneHot[float64]{Columns: []int{2}}
+	onehot.Fit(data)
+	if y := onehot.Transform(data[3]); !reflect.DeepEqual(y, []float64{4, 70, 0, 1, 0}) {
+		t.Fatalf("OneHot: %v", y)
+	}
+	labels := &LabelEncoder[float64]{Columns: []int{1}}
+	labels.Fit(data)
+	if y := labels.Transform([]float64{4, 30, 2}); !reflect.DeepEqual(y, []float64{4, 2, 2}) {
+		t.Fatalf("LabelEncoder: %v", y)
+	}
+}
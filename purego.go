@@ -0,0 +1,81 @@
+package gobrain
+
+// GoBackend is the portable pure Go backend
+type GoBackend struct{}
+
+func (GoBackend) Name() string {
+	return "go"
+}
+
+func (GoBackend) Dot64(X, Y []float64) float64 {
+	return goDot(X, Y)
+}
+
+func (GoBackend) Dot32(X, Y []float32) float32 {
+	return goDot(X, Y)
+}
+
+func (GoBackend) Scal64(alpha float64, X []float64) {
+	goScal(alpha, X)
+}
+
+func (GoBackend) Scal32(alpha float32, X []float32) {
+	goScal(alpha, X)
+}
+
+func (GoBackend) Axpy64(alpha float64, X []float64, Y []float64) {
+	goAxpy(alpha, X, Y)
+}
+
+func (GoBackend) Axpy32(alpha float32, X []float32, Y []float32) {
+	goAxpy(alpha, X, Y)
+}
+
+// The pure Go kernels are unrolled by four; dot keeps a single accumulator so
+// the result is the same as a naive loop on every architecture.
+
+func goDot[T Float](X, Y []T) T {
+	var sum T
+	n := len(X)
+	X, Y = X[:n], Y[:n]
+	i := 0
+	for ; i <= n-4; i += 4 {
+		sum += X[i] * Y[i]
+		sum += X[i+1] * Y[i+1]
+		sum += X[i+2] * Y[i+2]
+		sum += X[i+3] * Y[i+3]
+	}
+	for ; i < n; i++ {
+		sum += X[i] * Y[i]
+	}
+	return sum
+}
+
+func goScal[T Float](alpha T, X []T) {
+	n := len(X)
+	i := 0
+	for ; i <= n-4; i += 4 {
+		X[i] *= alpha
+		X[i+1] *= alpha
+		X[i+2] *= alpha
+		X[i+3] *= alpha
+	}
+	for ; i < n; i++ {
+		X[i] *= alpha
+	}
+}
+
+func goAxpy[T Float](alpha T, X []T, Y []T) {
+	n := len(X)
+	X, Y = X[:n], Y[:n]
+	i := 0
+	for ; i <= n-4; i += 4 {
+		Y[i] += alpha * X[i]
+		Y[i+1] += alpha * X[i+1]
+		Y[i+2] += alpha * X[i+2]
+		Y[i+3] += alpha * X[i+3]
+	}
+	for ; i < n; i++ {
+		Y[i] += alpha * X[i]
+	}
+}
@@ -19,10 +19,10 @@ func (nn *RNN32) Init(inputs, hiddens, outputs int) {
 	nn.NHiddens = hiddens + outputs
 	nn.NOutputs = outputs
 
-	nn.InputActivations = vector32(nn.NInputs, 1.0)
-	nn.HiddenActivations = vector32(nn.NHiddens, 1.0)
+	nn.InputActivations = vector[float32](nn.NInputs, 1.0)
+	nn.HiddenActivations = vector[float32](nn.NHiddens, 1.0)
 
-	nn.InputWeights = matrix32(nn.NHiddens, nn.NInputs)
+	nn.InputWeights = matrix[float32](nn.NHiddens, nn.NInputs)
 }
 
 func (nn *RNN32) SetWeights(weights []float32) {
@@ -57,12 +57,12 @@ func (nn *RNN32) Update(inputs []float32) []float32 {
 	} else {
 		for i := 0; i < nn.NOutputs; i++ {
 			sum := dot32(nn.InputActivations, nn.InputWeights[i])
-			nn.HiddenActivations[i] = tanh32(sum)
+			nn.HiddenActivations[i] = tanh(sum)
 		}
 	}
 	for i := nn.NOutputs; i < nn.NHiddens; i++ {
 		sum := dot32(nn.InputActivations, nn.InputWeights[i])
-		nn.HiddenActivations[i] = tanh32(sum)
+		nn.HiddenActivations[i] = tanh(sum)
 	}
 
 	return nn.HiddenActivations[:nn.NOutputs]
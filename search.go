@@ -0,0 +1,364 @@
+package gobrain
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+	"text/tabwriter"
+)
+
+// Hyperparameters are the values of the parameters of a trial by name
+type Hyperparameters map[string]float64
+
+// Names of the hyperparameters applied by Search itself
+const (
+	ParamHiddens = "hiddens"
+	ParamLRate   = "lrate"
+	ParamMFactor = "mfactor"
+	ParamDropout = "dropout"
+)
+
+/*
+Parameter is the set of values a hyperparameter can take.
+
+Grid search uses Values. Random search picks one of Values or, if it is not set,
+a value between Min and Max, sampled on a log scale if Log is set and rounded if Integer is set.
+*/
+type Parameter struct {
+	Values   []float64
+	Min, Max float64
+	Log      bool
+	Integer  bool
+}
+
+func (p Parameter) sample(rnd *rand.Rand) float64 {
+	if len(p.Values) > 0 {
+		return p.Values[int(random[float64](rnd, 0, float64(len(p.Values))))%len(p.Values)]
+	}
+	var v float64
+	if p.Log {
+		v = math.Exp(random[float64](rnd, math.Log(p.Min), math.Log(p.Max)))
+	} else {
+		v = random[float64](rnd, p.Min, p.Max)
+	}
+	if p.Integer {
+		v = math.Round(v)
+	}
+	return v
+}
+
+// SearchMethod selects how the configurations of a search are chosen
+type SearchMethod int
+
+const (
+	// GridSearch tries every combination of the values of the parameters
+	GridSearch SearchMethod = iota
+	// RandomSearch tries Trials random configurations
+	RandomSearch
+	// SuccessiveHalving trains Trials random configurations for a small number of iterations
+	// and keeps training the best 1/Eta of them for Eta times more iterations
+	SuccessiveHalving
+	// Hyperband runs successive halving with different trade offs between the number of configurations and iterations
+	Hyperband
+)
+
+// Search configures a hyperparameter search
+type Search[T Float] struct {
+	Method SearchMethod
+	Space  map[string]Parameter
+	// Number of configurations for random search and successive halving
+	Trials int
+	// Shape of the networks, the number of hidden nodes is the "hiddens" parameter
+	Inputs, Outputs int
+	Regression      bool
+	// Training iterations of a trial, the maximum for successive halving and Hyperband
+	Iterations int
+	// Minimum iterations of a trial for successive halving and Hyperband, Iterations / Eta^2 if not set
+	MinIterations int
+	// Reduction factor of successive halving and Hyperband, 3 if not set
+	Eta int
+	// Metric used to rank the trials, "mse" if not set, the metrics like accuracy are maximized and the errors minimized
+	Metric string
+	// Apply sets other parameters on the network and the training context
+	Apply func(params Hyperparameters, nn *Network[T], context *Context[T])
+	// Number of trials trained in parallel, GOMAXPROCS if not set
+	Workers int
+	// Random source of the search, the global source of math/rand if nil
+	Rand *rand.Rand
+}
+
+// Trial is a configuration evaluated by a search
+type Trial struct {
+	Params     Hyperparameters
+	Metrics    map[string]float64
+	Score      float64
+	Iterations int
+}
+
+// SearchResult holds the trials of a search ranked from best to worst
+type SearchResult struct {
+	Trials []Trial
+}
+
+// Best returns the best trial
+func (r *SearchResult) Best() Trial {
+	return r.Trials[0]
+}
+
+// String formats the ranked trials as a table
+func (r *SearchResult) String() string {
+	var names []string
+	if len(r.Trials) > 0 {
+		for name := range r.Trials[0].Params {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	buffer := &bytes.Buffer{}
+	w := tabwriter.NewWriter(buffer, 0, 4, 2, ' ', 0)
+	fmt.Fprint(w, "rank")
+	for _, name := range names {
+		fmt.Fprintf(w, "\t%s", name)
+	}
+	fmt.Fprint(w, "\titerations\tscore\n")
+	for i, trial := range r.Trials {
+		fmt.Fprintf(w, "%d", i+1)
+		for _, name := range names {
+			fmt.Fprintf(w, "\t%g", trial.Params[name])
+		}
+		fmt.Fprintf(w, "\t%d\t%g\n", trial.Iterations, trial.Score)
+	}
+	w.Flush()
+	return buffer.String()
+}
+
+type searchTrial[T Float] struct {
+	Trial
+	nn      *Network[T]
+	context *Context[T]
+}
+
+func (s *Search[T]) grid() []Hyperparameters {
+	var names []string
+	for name := range s.Space {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	configs := []Hyperparameters{{}}
+	for _, name := range names {
+		var next []Hyperparameters
+		for _, config := range configs {
+			for _, v := range s.Space[name].Values {
+				params := Hyperparameters{name: v}
+				for k, v := range config {
+					params[k] = v
+				}
+				next = append(next, params)
+			}
+		}
+		configs = next
+	}
+	return configs
+}
+
+func (s *Search[T]) random(n int) []Hyperparameters {
+	var names []string
+	for name := range s.Space {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	configs := make([]Hyperparameters, n)
+	for i := range configs {
+		configs[i] = Hyperparameters{}
+		for _, name := range names {
+			configs[i][name] = s.Space[name].sample(s.Rand)
+		}
+	}
+	return configs
+}
+
+func (s *Search[T]) newTrial(params Hyperparameters) *searchTrial[T] {
+	trial := &searchTrial[T]{
+		Trial: Trial{Params: params},
+		nn:    &Network[T]{Regression: s.Regression},
+		context: &Context[T]{
+			LRate:   0.6,
+			MFactor: 0.4,
+		},
+	}
+	hiddens := 2
+	if v, ok := params[ParamHiddens]; ok {
+		hiddens = int(v)
+	}
+	seed := rand.Int63()
+	if s.Rand != nil {
+		seed = s.Rand.Int63()
+	}
+	trial.nn.SetRand(rand.New(rand.NewSource(seed)))
+	trial.nn.Init(s.Inputs, hiddens, s.Outputs)
+	if v, ok := params[ParamDropout]; ok {
+		trial.nn.Dropout = T(v)
+	}
+	if v, ok := params[ParamLRate]; ok {
+		trial.context.LRate = T(v)
+	}
+	if v, ok := params[ParamMFactor]; ok {
+		trial.context.MFactor = T(v)
+	}
+	if s.Apply != nil {
+		s.Apply(params, trial.nn, trial.context)
+	}
+	return trial
+}
+
+// train trains every trial up to 'iterations' in parallel and evaluates it
+func (s *Search[T]) train(trials []*searchTrial[T], iterations int, train, validation Dataset[T]) {
+	workers := s.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	var wait sync.WaitGroup
+	semaphore := make(chan struct{}, workers)
+	for _, trial := range trials {
+		wait.Add(1)
+		semaphore <- struct{}{}
+		go func(trial *searchTrial[T]) {
+			defer func() {
+				<-semaphore
+				wait.Done()
+			}()
+			trial.nn.TrainDataset(train, func(context *Context[T]) *Context[T] {
+				activations := context.Activations
+				*context = *trial.context
+				if context.Activations == nil {
+					context.Activations = activations
+				}
+				context.Iterations = iterations - trial.Iterations
+				return context
+			})
+			trial.Iterations = iterations
+			trial.Metrics = trial.nn.Evaluate(validation).Map()
+			trial.Score = trial.Metrics[s.metric()]
+		}(trial)
+	}
+	wait.Wait()
+}
+
+func (s *Search[T]) metric() string {
+	if s.Metric == "" {
+		return "mse"
+	}
+	return s.Metric
+}
+
+func (s *Search[T]) rank(trials []*searchTrial[T]) {
+	maximize := maximized(s.metric())
+	sort.SliceStable(trials, func(i, j int) bool {
+		if maximize {
+			return trials[i].Score > trials[j].Score
+		}
+		return trials[i].Score < trials[j].Score
+	})
+}
+
+func (s *Search[T]) halving(configs []Hyperparameters, iterations int, train, validation Dataset[T]) []*searchTrial[T] {
+	eta := s.eta()
+	var all, alive []*searchTrial[T]
+	for _, params := range configs {
+		trial := s.newTrial(params)
+		all, alive = append(all, trial), append(alive, trial)
+	}
+	for {
+		s.train(alive, iterations, train, validation)
+		if iterations >= s.Iterations || len(alive) <= 1 {
+			break
+		}
+		s.rank(alive)
+		alive = alive[:int(math.Ceil(float64(len(alive))/float64(eta)))]
+		iterations *= eta
+		if iterations > s.Iterations {
+			iterations = s.Iterations
+		}
+	}
+	return all
+}
+
+func (s *Search[T]) eta() int {
+	if s.Eta < 2 {
+		return 3
+	}
+	return s.Eta
+}
+
+func (s *Search[T]) minIterations() int {
+	if s.MinIterations > 0 {
+		return s.MinIterations
+	}
+	eta := s.eta()
+	if min := s.Iterations / (eta * eta); min > 0 {
+		return min
+	}
+	return 1
+}
+
+/*
+Run trains a network for every configuration chosen by the search method on the 'train' dataset
+and ranks them by the metric computed on the 'validation' dataset.
+*/
+func (s *Search[T]) Run(train, validation Dataset[T]) (*SearchResult, error) {
+	if s.Iterations <= 0 {
+		return nil, fmt.Errorf("gobrain: the number of iterations must be positive")
+	}
+	if s.Method == GridSearch {
+		for name, parameter := range s.Space {
+			if len(parameter.Values) == 0 {
+				return nil, fmt.Errorf("gobrain: grid search needs the values of parameter %q", name)
+			}
+		}
+	} else if s.Method != Hyperband && s.Trials <= 0 {
+		return nil, fmt.Errorf("gobrain: the number of trials must be positive")
+	}
+	if _, ok := (Metrics{Regression: s.Regression}).Map()[s.metric()]; !ok {
+		return nil, fmt.Errorf("gobrain: unknown metric %q", s.metric())
+	}
+
+	var trials []*searchTrial[T]
+	switch s.Method {
+	case GridSearch:
+		for _, params := range s.grid() {
+			trials = append(trials, s.newTrial(params))
+		}
+		s.train(trials, s.Iterations, train, validation)
+	case RandomSearch:
+		for _, params := range s.random(s.Trials) {
+			trials = append(trials, s.newTrial(params))
+		}
+		s.train(trials, s.Iterations, train, validation)
+	case SuccessiveHalving:
+		trials = s.halving(s.random(s.Trials), s.minIterations(), train, validation)
+	case Hyperband:
+		eta := float64(s.eta())
+		max := int(math.Floor(math.Log(float64(s.Iterations)/float64(s.minIterations())) / math.Log(eta)))
+		for bracket := max; bracket >= 0; bracket-- {
+			n := int(math.Ceil(float64(max+1) / float64(bracket+1) * math.Pow(eta, float64(bracket))))
+			iterations := int(float64(s.Iterations) / math.Pow(eta, float64(bracket)))
+			if iterations < 1 {
+				iterations = 1
+			}
+			trials = append(trials, s.halving(s.random(n), iterations, train, validation)...)
+		}
+	default:
+		return nil, fmt.Errorf("gobrain: unknown search method %d", s.Method)
+	}
+
+	s.rank(trials)
+	result := &SearchResult{Trials: make([]Trial, len(trials))}
+	for i, trial := range trials {
+		result.Trials[i] = trial.Trial
+	}
+	return result, nil
+}
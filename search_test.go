@@ -0,0 +1,93 @@
+package gobrain
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestSearch(t *testing.T) {
+	data := testClassification(200, 1)
+	split := Split[float64](ToPatterns[float64](data), rand.New(rand.NewSource(1)), .75)
+	train, validation := Patterns[float64](split[0]), Patterns[float64](split[1])
+
+	searches := []*Search[float64]{
+		{
+			Method: GridSearch,
+			Space: map[string]Parameter{
+				ParamHiddens: {Values: []float64{1, 4}},
+				ParamLRate:   {Values: []float64{.001, .5}},
+			},
+		},
+		{
+			Method: RandomSearch,
+			Trials: 6,
+			Space: map[string]Parameter{
+				ParamHiddens: {Min: 1, Max: 6, Integer: true},
+				ParamLRate:   {Min: .001, Max: 1, Log: true},
+				ParamDropout: {Values: []float64{0, .1}},
+			},
+		},
+		{
+			Method: SuccessiveHalving,
+			Trials: 9,
+			Space: map[string]Parameter{
+				ParamLRate: {Min: .001, Max: 1, Log: true},
+			},
+		},
+		{
+			Method: Hyperband,
+			Space: map[string]Parameter{
+				ParamLRate:   {Min: .001, Max: 1, Log: true},
+				ParamMFactor: {Min: 0, Max: .5},
+			},
+		},
+	}
+	for _, search := range searches {
+		search.Inputs, search.Outputs = 2, 1
+		search.Iterations = 90
+		search.Metric = "accuracy"
+		search.Rand = rand.New(rand.NewSource(1))
+		result, err := search.Run(train, validation)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if search.Method == GridSearch && len(result.Trials) != 4 {
+			t.Fatalf("grid search ran %d trials", len(result.Trials))
+		}
+		for i := 1; i < len(result.Trials); i++ {
+			if result.Trials[i].Score > result.Trials[i-1].Score {
+				t.Fatalf("method %d: trials are not ranked", search.Method)
+			}
+		}
+		if best := result.Best(); best.Score < .9 || best.Iterations != 90 {
+			t.Fatalf("method %d: best trial %v\n%s", search.Method, best, result)
+		}
+		if !strings.HasPrefix(result.String(), "rank") {
+			t.Fatalf("table:\n%s", result)
+		}
+	}
+
+	applied := 0
+	search := &Search[float64]{
+		Method:     RandomSearch,
+		Trials:     2,
+		Space:      map[string]Parameter{"shuffle": {Values: []float64{1}}},
+		Inputs:     2,
+		Outputs:    1,
+		Iterations: 1,
+		Workers:    1,
+		Apply: func(params Hyperparameters, nn *Network[float64], context *Context[float64]) {
+			context.Shuffle = params["shuffle"] == 1
+			applied++
+		},
+	}
+	if _, err := search.Run(train, validation); err != nil || applied != 2 {
+		t.Fatalf("Apply was called %d times: %v", applied, err)
+	}
+
+	search.Metric = "r2"
+	if _, err := search.Run(train, validation); err == nil {
+		t.Fatal("expected an error for a regression metric of a classifier")
+	}
+}
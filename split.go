@@ -0,0 +1,117 @@
+package gobrain
+
+import (
+	"math"
+	"math/rand"
+)
+
+// class returns the class of a target, the index of the largest value or, for a single output, whether it is at least 0.5
+func class[T Float](target []T) int {
+	if len(target) == 1 {
+		if target[0] >= .5 {
+			return 1
+		}
+		return 0
+	}
+	max := 0
+	for i, v := range target {
+		if v > target[max] {
+			max = i
+		}
+	}
+	return max
+}
+
+// boundaries returns the end of every part of n items split with fractions, the remainder is an extra part
+func boundaries(n int, fractions []float64) []int {
+	var (
+		ends []int
+		sum  float64
+	)
+	for _, fraction := range fractions {
+		sum += fraction
+		end := int(math.Round(sum * float64(n)))
+		if end > n {
+			end = n
+		}
+		ends = append(ends, end)
+	}
+	if sum < 1-1e-9 {
+		ends = append(ends, n)
+	}
+	return ends
+}
+
+func parts[T Float](patterns [][][]T, order []int, fractions []float64) [][][][]T {
+	ends := boundaries(len(order), fractions)
+	split, start := make([][][][]T, len(ends)), 0
+	for i, end := range ends {
+		split[i] = make([][][]T, 0, end-start)
+		for _, j := range order[start:end] {
+			split[i] = append(split[i], patterns[j])
+		}
+		start = end
+	}
+	return split
+}
+
+/*
+Split shuffles patterns with rnd, or the global source of math/rand if nil, and splits them into parts.
+
+Every fraction is the size of a part relative to the number of patterns, the patterns which are left
+form an extra part, so Split(patterns, rnd, .7, .15) returns training, validation and test sets.
+*/
+func Split[T Float](patterns [][][]T, rnd *rand.Rand, fractions ...float64) [][][][]T {
+	order := make([]int, len(patterns))
+	for i := range order {
+		order[i] = i
+	}
+	shuffle(rnd, len(order), func(a, b int) {
+		order[a], order[b] = order[b], order[a]
+	})
+	return parts(patterns, order, fractions)
+}
+
+/*
+StratifiedSplit is like Split but keeps the proportion of every class the same in all the parts.
+
+The class of a pattern is the index of its largest target or, for a single target, whether it is at least 0.5.
+*/
+func StratifiedSplit[T Float](patterns [][][]T, rnd *rand.Rand, fractions ...float64) [][][][]T {
+	var classes [][]int
+	for i, p := range patterns {
+		c := class(p[1])
+		for len(classes) <= c {
+			classes = append(classes, nil)
+		}
+		classes[c] = append(classes[c], i)
+	}
+
+	var split [][][][]T
+	for _, order := range classes {
+		shuffle(rnd, len(order), func(a, b int) {
+			order[a], order[b] = order[b], order[a]
+		})
+		for i, part := range parts(patterns, order, fractions) {
+			for len(split) <= i {
+				split = append(split, nil)
+			}
+			split[i] = append(split[i], part...)
+		}
+	}
+	for _, part := range split {
+		shuffle(rnd, len(part), func(a, b int) {
+			part[a], part[b] = part[b], part[a]
+		})
+	}
+	return split
+}
+
+// TimeSplit splits time ordered patterns into consecutive parts like Split, without shuffling them
+func TimeSplit[T Float](patterns [][][]T, fractions ...float64) [][][][]T {
+	order := make([]int, len(patterns))
+	for i := range order {
+		order[i] = i
+	}
+	return parts(patterns, order, fractions)
+}
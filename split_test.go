@@ -0,0 +1,120 @@
+package gobrain
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func testPatterns(n int) [][][]float64 {
+	patterns := make([][][]float64, n)
+	for i := range patterns {
+		patterns[i] = [][]float64{{float64(i)}, {float64(i % 4 / 3)}}
+	}
+	return patterns
+}
+
+func TestSplit(t *testing.T) {
+	patterns := testPatterns(100)
+	split := Split(patterns, rand.New(rand.NewSource(1)), .7, .15)
+	if len(split) != 3 || len(split[0]) != 70 || len(split[1]) != 15 || len(split[2]) != 15 {
+		t.Fatalf("got parts of %d %d %d", len(split[0]), len(split[1]), len(split[2]))
+	}
+	seen := make(map[float64]bool)
+	for _, part := range split {
+		for _, p := range part {
+			seen[p[0][0]] = true
+		}
+	}
+	if len(seen) != 100 {
+		t.Fatalf("got %d distinct patterns", len(seen))
+	}
+
+	split = TimeSplit(patterns, .8)
+	if len(split) != 2 || split[0][79][0][0] != 79 || split[1][0][0][0] != 80 {
+		t.Fatal("time split is not ordered")
+	}
+}
+
+func TestStratifiedSplit(t *testing.T) {
+	patterns := testPatterns(100)
+	split := StratifiedSplit(patterns, rand.New(rand.NewSource(1)), .6, .2)
+	if len(split) != 3 {
+		t.Fatalf("got %d parts", len(split))
+	}
+	for i, size := range []int{60, 20, 20} {
+		positive := 0
+		for _, p := range split[i] {
+			positive += class(p[1])
+		}
+		if len(split[i]) != size || positive != size/4 {
+			t.Fatalf("part %d has %d patterns, %d positive", i, len(split[i]), positive)
+		}
+	}
+}
+
+func TestShuffle(t *testing.T) {
+	var visited []float64
+	data := Lazy[float64]{
+		N: 8,
+		Load: func(i int) Sample[float64] {
+			visited = append(visited, float64(i))
+			return Sample[float64]{Input: []float64{float64(i), 0}, Target: []float64{0}}
+		},
+	}
+	epochs := func(seed int64) []float64 {
+		visited = nil
+		nn, _ := xorNetwork[float64](0, rand.New(rand.NewSource(1)))
+		nn.SetRand(rand.New(rand.NewSource(seed)))
+		nn.TrainDataset(data, func(context *Context[float64]) *Context[float64] {
+			context.Iterations, context.Shuffle = 2, true
+			return context
+		})
+		return visited
+	}
+	a, b := epochs(1), epochs(1)
+	ordered := true
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatal("shuffling doesn't use the random source of the network")
+		}
+		ordered = ordered && a[i] == float64(i%8)
+	}
+	if ordered {
+		t.Fatal("samples were not shuffled")
+	}
+
+	sample := func(x float64) Sample[float64] {
+		return Sample[float64]{Input: []float64{x, 0}, Target: []float64{0}}
+	}
+	sequences := NewSequences([][]Sample[float64]{
+		{sample(0), sample(1), sample(2)},
+		{sample(3), sample(4)},
+		{sample(5)},
+		{sample(6), sample(7)},
+	})
+	recorder := &recordingSequences{Sequences: sequences}
+	nn, _ := xorNetwork[float64](0, rand.New(rand.NewSource(1)))
+	nn.SetRand(rand.New(rand.NewSource(2)))
+	nn.TrainDataset(recorder, func(context *Context[float64]) *Context[float64] {
+		context.Iterations, context.Shuffle = 1, true
+		return context
+	})
+	if recorder.visited[0] == 0 {
+		t.Fatalf("sequences were not shuffled: %v", recorder.visited)
+	}
+	for i, j := range recorder.visited {
+		if !sequences.SequenceStart(j) && (i == 0 || recorder.visited[i-1] != j-1) {
+			t.Fatalf("sequences were split: %v", recorder.visited)
+		}
+	}
+}
+
+type recordingSequences struct {
+	*Sequences[float64]
+	visited []int
+}
+
+func (r *recordingSequences) Sample(i int) Sample[float64] {
+	r.visited = append(r.visited, i)
+	return r.Sequences.Sample(i)
+}
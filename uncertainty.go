@@ -0,0 +1,42 @@
+package gobrain
+
+import "math"
+
+// UpdateWithDropout activates the Network with dropout applied to the hidden layer like when training
+func (nn *Network[T]) UpdateWithDropout(inputs []T) []T {
+	output, _ := nn.outputActivation()
+	return nn.update(inputs, nn.dropout(), output)
+}
+
+/*
+MCDropout estimates the uncertainty of a prediction with Monte Carlo dropout, it runs 'samples' forward passes
+with dropout active and returns the mean and the standard deviation of every output.
+
+The dropout masks are drawn from the random source of the Network. The contexts are left unchanged.
+*/
+func (nn *Network[T]) MCDropout(inputs []T, samples int) (mean, std []T) {
+	contexts := nn.contexts()
+	defer nn.restoreContexts(contexts)
+
+	hidden := nn.dropout()
+	output, _ := nn.outputActivation()
+	// Welford's online algorithm
+	means, squares := make([]float64, nn.NOutputs), make([]float64, nn.NOutputs)
+	for s := 1; s <= samples; s++ {
+		nn.restoreContexts(contexts)
+		for i, y := range nn.update(inputs, hidden, output) {
+			d := float64(y) - means[i]
+			means[i] += d / float64(s)
+			squares[i] += d * (float64(y) - means[i])
+		}
+	}
+
+	mean, std = make([]T, nn.NOutputs), make([]T, nn.NOutputs)
+	for i := range mean {
+		mean[i] = T(means[i])
+		if samples > 1 {
+			std[i] = T(math.Sqrt(squares[i] / float64(samples-1)))
+		}
+	}
+	return mean, std
+}
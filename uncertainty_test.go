@@ -0,0 +1,53 @@
+package gobrain
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func testMCDropout[T Float](t *testing.T) {
+	nn, patterns := xorNetwork[T](0.5, rand.New(rand.NewSource(1)))
+	nn.SetRand(rand.New(rand.NewSource(1)))
+	input := patterns[1][0]
+
+	mean, std := nn.MCDropout(input, 200)
+	if len(mean) != 1 || len(std) != 1 {
+		t.Fatalf("got %d means and %d deviations, want 1", len(mean), len(std))
+	}
+	if std[0] <= 0 {
+		t.Fatalf("deviation %v with dropout, want positive", std[0])
+	}
+
+	// the mean and deviation of the same passes computed directly
+	nn.SetRand(rand.New(rand.NewSource(1)))
+	outputs := make([]float64, 200)
+	var sum float64
+	for i := range outputs {
+		outputs[i] = float64(nn.UpdateWithDropout(input)[0])
+		sum += outputs[i]
+	}
+	m := sum / float64(len(outputs))
+	var squares float64
+	for _, y := range outputs {
+		squares += (y - m) * (y - m)
+	}
+	s := math.Sqrt(squares / float64(len(outputs)-1))
+	if !closeTo(float64(mean[0]), m, 1e-5) || !closeTo(float64(std[0]), s, 1e-3) {
+		t.Fatalf("got mean %v and deviation %v, want %v and %v", mean[0], std[0], m, s)
+	}
+
+	nn.Dropout = 0
+	mean, std = nn.MCDropout(input, 10)
+	if std[0] != 0 || !closeTo(float64(mean[0]), float64(nn.Update(input)[0]), 1e-6) {
+		t.Fatalf("got mean %v and deviation %v without dropout", mean[0], std[0])
+	}
+}
+
+func TestMCDropout(t *testing.T) {
+	testMCDropout[float64](t)
+}
+
+func TestMCDropout32(t *testing.T) {
+	testMCDropout[float32](t)
+}
@@ -1,16 +1,50 @@
 package gobrain
 
 import (
+	"fmt"
 	"math"
 	"math/rand"
 )
 
-func random(a, b float64) float64 {
-	return (b-a)*rand.Float64() + a
+// random returns a random number in [a, b) from rnd, or from the global source if rnd is nil
+func random[T Float](rnd *rand.Rand, a, b T) T {
+	var r T
+	switch any(r).(type) {
+	case float32:
+		if rnd != nil {
+			r = T(rnd.Float32())
+		} else {
+			r = T(rand.Float32())
+		}
+	default:
+		if rnd != nil {
+			r = T(rnd.Float64())
+		} else {
+			r = T(rand.Float64())
+		}
+	}
+	return (b-a)*r + a
+}
+
+// normal returns a standard normal random number from rnd, or from the global source if rnd is nil
+func normal[T Float](rnd *rand.Rand) T {
+	if rnd != nil {
+		return T(rnd.NormFloat64())
+	}
+	return T(rand.NormFloat64())
+}
+
+// shuffle shuffles with rnd, or with the global source if rnd is nil
+func shuffle(rnd *rand.Rand, n int, swap func(i, j int)) {
+	if rnd != nil {
+		rnd.Shuffle(n, swap)
+	} else {
+		rand.Shuffle(n, swap)
+	}
 }
 
-func matrix(I, J int) [][]float64 {
-	m, dense, offset := make([][]float64, I), make([]float64, I*J), 0
+func matrix[T Float](I, J int) [][]T {
+	m, dense, offset := make([][]T, I), make([]T, I*J), 0
 	for i := 0; i < I; i++ {
 		m[i] = dense[offset : offset+J]
 		offset += J
@@ -18,69 +52,161 @@ func matrix(I, J int) [][]float64 {
 	return m
 }
 
-func vector(I int, fill float64) []float64 {
-	v := make([]float64, I)
+// clone returns a copy of m backed by a new row major buffer
+func clone[T Float](m [][]T) [][]T {
+	if m == nil {
+		return nil
+	}
+	cols := 0
+	if len(m) > 0 {
+		cols = len(m[0])
+	}
+	c := matrix[T](len(m), cols)
+	for i, row := range m {
+		copy(c[i], row)
+	}
+	return c
+}
+
+// flat returns the dense buffer backing the rows of m, the rows are repacked into a new buffer if they are not contiguous
+func flat[T Float](m [][]T) []T {
+	if len(m) == 0 {
+		return nil
+	}
+	cols := len(m[0])
+	if n := len(m) * cols; cap(m[0]) >= n {
+		dense, contiguous := m[0][:n], true
+		for i, row := range m {
+			if len(row) != cols || (cols > 0 && &row[0] != &dense[i*cols]) {
+				contiguous = false
+				break
+			}
+		}
+		if contiguous {
+			return dense
+		}
+	}
+	dense := make([]T, len(m)*cols)
+	for i, row := range m {
+		copy(dense[i*cols:(i+1)*cols], row)
+		m[i] = dense[i*cols : (i+1)*cols]
+	}
+	return dense
+}
+
+func vector[T Float](I int, fill T) []T {
+	v := make([]T, I)
 	for i := 0; i < I; i++ {
 		v[i] = fill
 	}
 	return v
 }
 
-func sigmoid(x float64) float64 {
-	return 1 / (1 + math.Exp(-x))
+func dot[T Float](X, Y []T) T {
+	switch x := any(X).(type) {
+	case []float32:
+		return T(dot32(x, any(Y).([]float32)))
+	default:
+		return T(dot64(any(X).([]float64), any(Y).([]float64)))
+	}
 }
 
-func dsigmoid(y float64) float64 {
-	return y * (1 - y)
+func scal[T Float](alpha T, X []T) {
+	switch x := any(X).(type) {
+	case []float32:
+		scal32(float32(alpha), x)
+	default:
+		scal64(float64(alpha), any(X).([]float64))
+	}
 }
 
-func normalize(a float64) float64 {
-	if a > 1 {
-		return 1
-	} else if a < 0 {
-		return 0
+func axpy[T Float](alpha T, X []T, Y []T) {
+	switch x := any(X).(type) {
+	case []float32:
+		axpy32(float32(alpha), x, any(Y).([]float32))
+	default:
+		axpy64(float64(alpha), any(X).([]float64), any(Y).([]float64))
 	}
-	return a
 }
 
-func random32(a, b float32) float32 {
-	return (b-a)*rand.Float32() + a
+func tanh[T Float](x T) T {
+	return 2/(1+T(math.Exp(-2*float64(x)))) - 1
 }
 
-func matrix32(I, J int) [][]float32 {
-	m, dense, offset := make([][]float32, I), make([]float32, I*J), 0
-	for i := 0; i < I; i++ {
-		m[i] = dense[offset : offset+J]
-		offset += J
+func dtanh[T Float](y T) T {
+	return 1 - y*y
+}
+
+func relu[T Float](x T) T {
+	if x < 0 {
+		return 0
 	}
-	return m
+	return x
 }
 
-func vector32(I int, fill float32) []float32 {
-	v := make([]float32, I)
-	for i := 0; i < I; i++ {
-		v[i] = fill
+func drelu[T Float](y T) T {
+	if y > 0 {
+		return 1
 	}
-	return v
+	return 0
 }
 
-func tanh32(x float32) float32 {
-	return 2/(1+float32(math.Exp(-2*float64(x)))) - 1
+func linear[T Float](x T) T {
+	return x
 }
 
-func dtanh32(y float32) float32 {
-	return 1 - y*y
+func dlinear[T Float](y T) T {
+	return 1
 }
 
-func sigmoid32(x float32) float32 {
-	return 1 / (1 + float32(math.Exp(-float64(x))))
+func sigmoid[T Float](x T) T {
+	return 1 / (1 + T(math.Exp(-float64(x))))
 }
 
-func dsigmoid32(y float32) float32 {
+func dsigmoid[T Float](y T) T {
 	return y * (1 - y)
 }
 
-func normalize32(a float32) float32 {
+// activations are the built in activation functions and their derivatives by name, the
+// function values are created once so that Save can recognize them and store their names
+var activations32, activations64 = activationTable[float32](), activationTable[float64]()
+
+func activationTable[T Float]() map[string]Activation[T] {
+	return map[string]Activation[T]{
+		"sigmoid": sigmoid[T], "dsigmoid": dsigmoid[T],
+		"tanh": tanh[T], "dtanh": dtanh[T],
+		"relu": relu[T], "drelu": drelu[T],
+		"linear": linear[T], "dlinear": dlinear[T],
+	}
+}
+
+func activations[T Float]() map[string]Activation[T] {
+	if table, ok := any(activations32).(map[string]Activation[T]); ok {
+		return table
+	}
+	return any(activations64).(map[string]Activation[T])
+}
+
+// builtin returns the built in activation function 'name'
+func builtin[T Float](name string) Activation[T] {
+	return activations[T]()[name]
+}
+
+/*
+NamedActivation returns the built in activation function 'name', which is one of
+sigmoid, tanh, relu and linear, and its derivative.
+
+Networks and layers using only built in activation functions can be saved with Model.Save.
+*/
+func NamedActivation[T Float](name string) (Activation[T], Activation[T], error) {
+	activation, dactivation := builtin[T](name), builtin[T]("d"+name)
+	if activation == nil || dactivation == nil {
+		return nil, nil, fmt.Errorf("gobrain: unknown activation %q", name)
+	}
+	return activation, dactivation, nil
+}
+
+func normalize[T Float](a T) T {
 	if a > 1 {
 		return 1
 	} else if a < 0 {
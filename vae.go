@@ -0,0 +1,144 @@
+package gobrain
+
+import (
+	"math"
+	"math/rand"
+)
+
+/*
+VAE is a variational autoencoder. The Encoder outputs the mean and the log variance of the code,
+the code is sampled from them with the reparameterization trick and the Decoder reconstructs the inputs from it.
+
+It is trained to minimize the loss sum((x - reconstruction)^2) / 2 + KLWeight * KL, where KL is the
+Kullback-Leibler divergence of the distribution of the code from the standard normal prior.
+*/
+type VAE[T Float] struct {
+	Encoder, Decoder *Network[T]
+	// Size of the code
+	Latent int
+	// Weight of the KL divergence, 1 if not set for a standard VAE and larger for a beta-VAE.
+	// With few inputs the reconstruction error is small and a smaller weight keeps the code from collapsing to the prior
+	KLWeight T
+
+	rnd                          *rand.Rand
+	epsilon, z, dz, targets, std []T
+}
+
+// SetRand sets the random source used to initialize, sample and train, it must be called before Init
+func (v *VAE[T]) SetRand(rnd *rand.Rand) {
+	v.rnd = rnd
+}
+
+// Init initializes a VAE of 'inputs' inputs with 'hiddens' hidden nodes in the encoder and the decoder and a code of size 'latent'
+func (v *VAE[T]) Init(inputs, hiddens, latent int) {
+	v.Latent = latent
+	if v.KLWeight == 0 {
+		v.KLWeight = 1
+	}
+	v.Encoder = &Network[T]{Regression: true}
+	v.Encoder.SetRand(v.rnd)
+	v.Encoder.Init(inputs, hiddens, 2*latent)
+	v.Decoder = &Network[T]{}
+	v.Decoder.SetRand(v.rnd)
+	v.Decoder.Init(latent, hiddens, inputs)
+	v.buffers()
+}
+
+func (v *VAE[T]) buffers() {
+	if len(v.z) == v.Latent {
+		return
+	}
+	v.epsilon, v.z, v.dz = make([]T, v.Latent), make([]T, v.Latent), make([]T, v.Latent)
+	v.std, v.targets = make([]T, v.Latent), make([]T, 2*v.Latent)
+}
+
+// Encode returns the mean and the log variance of the code of the inputs
+func (v *VAE[T]) Encode(inputs []T) (mean, logvar []T) {
+	outputs := v.Encoder.Update(inputs)
+	return append([]T(nil), outputs[:v.Latent]...), append([]T(nil), outputs[v.Latent:]...)
+}
+
+// Decode returns the reconstruction of the inputs from a code
+func (v *VAE[T]) Decode(code []T) []T {
+	return v.Decoder.Update(code)
+}
+
+// Reconstruct decodes the mean of the code of the inputs
+func (v *VAE[T]) Reconstruct(inputs []T) []T {
+	return v.Decoder.Update(v.Encoder.Update(inputs)[:v.Latent])
+}
+
+// Sample generates new data by decoding a code drawn from the standard normal prior
+func (v *VAE[T]) Sample() []T {
+	code := make([]T, v.Latent)
+	for i := range code {
+		code[i] = normal[T](v.rnd)
+	}
+	return v.Decode(code)
+}
+
+/*
+gradient adds the gradients of the loss for the inputs and the noise 'epsilon' to 'encoder' and 'decoder'
+and returns the reconstruction error and the KL divergence.
+*/
+func (v *VAE[T]) gradient(inputs, epsilon []T, encoder, decoder *Gradient[T]) (reconstruction, kl T) {
+	v.buffers()
+	outputs := v.Encoder.Update(inputs)
+	mean, logvar := outputs[:v.Latent], outputs[v.Latent:]
+	// reparameterization, z = mean + std * epsilon
+	for i := range v.z {
+		v.std[i] = T(math.Exp(float64(logvar[i]) / 2))
+		v.z[i] = mean[i] + v.std[i]*epsilon[i]
+	}
+
+	v.Decoder.Update(v.z)
+	reconstruction = v.Decoder.ComputeGradient(inputs, decoder) / 2
+	v.Decoder.lossInputGradient(v.dz)
+
+	// the encoder outputs are linear so targets shifted by the gradient of the loss give it to ComputeGradient
+	beta := v.KLWeight
+	for i := range v.z {
+		variance := v.std[i] * v.std[i]
+		kl -= (1 + logvar[i] - mean[i]*mean[i] - variance) / 2
+		dmean := v.dz[i] + beta*mean[i]
+		dlogvar := v.dz[i]*epsilon[i]*v.std[i]/2 + beta*(variance-1)/2
+		v.targets[i] = mean[i] - dmean
+		v.targets[v.Latent+i] = logvar[i] - dlogvar
+	}
+	v.Encoder.ComputeGradient(v.targets, encoder)
+	return reconstruction, kl
+}
+
+/*
+Train trains the VAE on the rows of data, one sample at a time, and returns the mean loss of every epoch.
+
+The Iterations, LRate, MFactor, Shuffle and Debug fields of the context are used, and the callbacks but OnGradient.
+*/
+func (v *VAE[T]) Train(data [][]T, config Config[T]) []T {
+	context := &Context[T]{
+		Iterations: 10,
+		LRate:      0.6,
+		MFactor:    0.4,
+	}
+	if config != nil {
+		context = config(context)
+	}
+
+	encoder, decoder := v.Encoder.NewGradient(), v.Decoder.NewGradient()
+	samples := make(Samples[T], len(data))
+	for i, row := range data {
+		samples[i] = Sample[T]{Input: row}
+	}
+	// the encoder shares the random source of the VAE, the loop shuffles with it and prints to the output of the encoder
+	return v.Encoder.epochs(samples, context, func(p Sample[T]) (T, int) {
+		for j := range v.epsilon {
+			v.epsilon[j] = normal[T](v.rnd)
+		}
+		encoder.Zero()
+		decoder.Zero()
+		reconstruction, kl := v.gradient(p.Input, v.epsilon, encoder, decoder)
+		v.Encoder.ApplyGradient(encoder, context.LRate, context.MFactor)
+		v.Decoder.ApplyGradient(decoder, context.LRate, context.MFactor)
+		return reconstruction + v.KLWeight*kl, 1
+	})
+}
@@ -0,0 +1,91 @@
+package gobrain
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestVAEGradient(t *testing.T) {
+	v := &VAE[float64]{KLWeight: .5}
+	v.SetRand(rand.New(rand.NewSource(1)))
+	v.Init(4, 5, 2)
+	inputs, epsilon := []float64{.9, .1, .8, .2}, []float64{.3, -1.2}
+
+	encoder, decoder := v.Encoder.NewGradient(), v.Decoder.NewGradient()
+	v.gradient(inputs, epsilon, encoder, decoder)
+	loss := func() float64 {
+		reconstruction, kl := v.gradient(inputs, epsilon, v.Encoder.NewGradient(), v.Decoder.NewGradient())
+		return float64(reconstruction + v.KLWeight*kl)
+	}
+
+	check := func(name string, weights, gradients [][]float64) {
+		const epsilon = 1e-6
+		for i, row := range weights {
+			for j, w := range row {
+				row[j] = w + epsilon
+				plus := loss()
+				row[j] = w - epsilon
+				minus := loss()
+				row[j] = w
+				numerical := (plus - minus) / (2 * epsilon)
+				if e := relativeError(gradients[i][j], numerical); e > 1e-4 && math.Abs(gradients[i][j]-numerical) > 1e-9 {
+					t.Fatalf("%s weight %d %d: gradient %v, want %v", name, i, j, gradients[i][j], numerical)
+				}
+			}
+		}
+	}
+	check("encoder input", v.Encoder.InputWeights, encoder.Input)
+	check("encoder output", v.Encoder.OutputWeights, encoder.Output)
+	check("decoder input", v.Decoder.InputWeights, decoder.Input)
+	check("decoder output", v.Decoder.OutputWeights, decoder.Output)
+}
+
+func TestVAE(t *testing.T) {
+	prototypes := [][]float64{
+		{1, 1, 0, 0, 0, 0},
+		{0, 0, 1, 1, 0, 0},
+		{0, 0, 0, 0, 1, 1},
+		{1, 0, 1, 0, 1, 0},
+	}
+	var data [][]float64
+	for i := 0; i < 25; i++ {
+		data = append(data, prototypes...)
+	}
+
+	v := &VAE[float64]{KLWeight: .05}
+	v.SetRand(rand.New(rand.NewSource(1)))
+	v.Init(6, 8, 2)
+	errors := v.Train(data, func(context *Context[float64]) *Context[float64] {
+		context.Iterations = 200
+		context.LRate, context.MFactor = .1, .5
+		context.Shuffle = true
+		return context
+	})
+	if errors[len(errors)-1] >= errors[0]/2 {
+		t.Fatalf("loss went from %v to %v", errors[0], errors[len(errors)-1])
+	}
+
+	for i, prototype := range prototypes {
+		reconstruction := v.Reconstruct(prototype)
+		for j, x := range prototype {
+			if math.Abs(reconstruction[j]-x) > .5 {
+				t.Fatalf("prototype %d reconstructed as %v", i, reconstruction)
+			}
+		}
+		mean, logvar := v.Encode(prototype)
+		if len(mean) != 2 || len(logvar) != 2 {
+			t.Fatalf("got a mean of size %d and a log variance of size %d, want 2", len(mean), len(logvar))
+		}
+	}
+
+	sample := v.Sample()
+	if len(sample) != 6 {
+		t.Fatalf("got a sample of size %d, want 6", len(sample))
+	}
+	for _, x := range sample {
+		if x < 0 || x > 1 {
+			t.Fatalf("sample %v outside of [0, 1]", sample)
+		}
+	}
+}